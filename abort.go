@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultAbortGrace is how long a build may take to stop before escalating
+	defaultAbortGrace = 10 * time.Second
+	// abortTimeout bounds the total time spent aborting a single job
+	abortTimeout = time.Minute
+)
+
+// abortActions are the build endpoints used to stop a running build, in escalating order.
+// term and kill are only available for Pipeline builds.
+var abortActions = []string{"stop", "term", "kill"}
+
+// abort cancels the queue item if the build has not started yet,
+// otherwise it stops the running build.
+func (jenkins *Jenkins) abort(ctx context.Context, job string, queueID, buildNumber int) error {
+	if buildNumber == 0 {
+		if err := jenkins.cancelQueueItem(ctx, queueID); err != nil {
+			return err
+		}
+		slog.Info("cancelled queue item", "job", job, "queue_id", queueID)
+
+		// The build may have started while the cancel request was in flight
+		queueItem, err := jenkins.getQueueItem(ctx, queueID)
+		if err != nil || queueItem.Executable == nil || queueItem.Executable.Number == 0 {
+			return nil
+		}
+		buildNumber = queueItem.Executable.Number
+	}
+
+	return jenkins.stopBuild(ctx, job, buildNumber)
+}
+
+// cancelQueueItem removes a pending item from the Jenkins build queue
+func (jenkins *Jenkins) cancelQueueItem(ctx context.Context, queueID int) error {
+	params := url.Values{"id": []string{strconv.Itoa(queueID)}}
+	if _, _, err := jenkins.post(ctx, "/queue/cancelItem", params, nil); err != nil {
+		// Jenkins answers 404 once the item has left the queue
+		if isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to cancel queue item %d: %w", queueID, err)
+	}
+
+	return nil
+}
+
+// stopBuild stops a running build, escalating from stop to term to kill
+// when the build is still running after the grace period.
+func (jenkins *Jenkins) stopBuild(ctx context.Context, job string, buildNumber int) error {
+	for _, action := range abortActions {
+		path := fmt.Sprintf("%s/%d/%s", jenkins.parseJobPath(job), buildNumber, action)
+		if _, _, err := jenkins.post(ctx, path, nil, nil); err != nil {
+			slog.Warn(
+				"failed to stop job",
+				"job", job,
+				"build_number", buildNumber,
+				"action", action,
+				"error", err,
+			)
+			continue
+		}
+		slog.Info("requested job stop", "job", job, "build_number", buildNumber, "action", action)
+
+		stopped, err := jenkins.waitForStop(ctx, job, buildNumber)
+		if err != nil {
+			return err
+		}
+		if stopped {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("job %s (build #%d) is still running after abort", job, buildNumber)
+}
+
+// waitForStop polls the build until it is no longer running or the grace period elapses
+func (jenkins *Jenkins) waitForStop(ctx context.Context, job string, buildNumber int) (bool, error) {
+	deadline := time.Now().Add(jenkins.abortGrace)
+	interval := min(time.Second, jenkins.abortGrace)
+
+	for {
+		buildInfo, err := jenkins.getBuildInfo(ctx, job, buildNumber)
+		if err == nil && !buildInfo.Building {
+			return true, nil
+		}
+
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return false, err
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// abortRecorder records the POST requests received by a mock Jenkins server
+type abortRecorder struct {
+	mu    sync.Mutex
+	posts []string
+}
+
+func (r *abortRecorder) add(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.posts = append(r.posts, path)
+}
+
+func (r *abortRecorder) paths() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.posts...)
+}
+
+func TestAbortQueuedBuild(t *testing.T) {
+	recorder := &abortRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			recorder.add(r.URL.Path + "?" + r.URL.RawQuery)
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == testQueueItemPath:
+			_, _ = w.Write([]byte(`{"id":123,"why":"Waiting for executor"}`))
+		}
+	}))
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+
+	err = jenkins.abort(context.Background(), testJobName, 123, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/queue/cancelItem?id=123"}, recorder.paths())
+}
+
+func TestAbortRunningBuild(t *testing.T) {
+	tests := []struct {
+		name      string
+		stopAfter string // Action after which the build reports as stopped
+		expected  []string
+		wantError bool
+	}{
+		{
+			name:      "stop succeeds",
+			stopAfter: "/job/test-job/456/stop",
+			expected:  []string{"/job/test-job/456/stop"},
+		},
+		{
+			name:      "escalates to term",
+			stopAfter: "/job/test-job/456/term",
+			expected:  []string{"/job/test-job/456/stop", "/job/test-job/456/term"},
+		},
+		{
+			name: "still running after kill",
+			expected: []string{
+				"/job/test-job/456/stop",
+				"/job/test-job/456/term",
+				"/job/test-job/456/kill",
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &abortRecorder{}
+			var mu sync.Mutex
+			stopped := false
+
+			server := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					if r.Method == http.MethodPost {
+						recorder.add(r.URL.Path)
+						if r.URL.Path == tt.stopAfter {
+							stopped = true
+						}
+						w.WriteHeader(http.StatusOK)
+						return
+					}
+
+					if stopped {
+						_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"ABORTED"}`))
+					} else {
+						_, _ = w.Write([]byte(`{"number":456,"building":true,"result":null}`))
+					}
+				}),
+			)
+			defer server.Close()
+
+			jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+			assert.NoError(t, err)
+			jenkins.abortGrace = 20 * time.Millisecond
+
+			err = jenkins.abort(context.Background(), testJobName, 123, 456)
+			if tt.wantError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "still running after abort")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, recorder.paths())
+		})
+	}
+}
+
+func TestWaitForCompletionAbortOnCancel(t *testing.T) {
+	t.Run("timeout aborts running build", func(t *testing.T) {
+		recorder := &abortRecorder{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPost:
+				recorder.add(r.URL.Path)
+				w.WriteHeader(http.StatusOK)
+			case r.URL.Path == testQueueItemPath:
+				_, _ = w.Write([]byte(`{"id":123,"executable":{"number":456}}`))
+			case r.URL.Path == testBuildStatusPath:
+				if len(recorder.paths()) > 0 {
+					_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"ABORTED"}`))
+				} else {
+					_, _ = w.Write([]byte(`{"number":456,"building":true,"result":null}`))
+				}
+			}
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+		jenkins.AbortOnCancel = true
+
+		_, err = jenkins.waitForCompletion(
+			context.Background(),
+			testJobName,
+			123,
+			20*time.Millisecond,
+			100*time.Millisecond,
+		)
+
+		assert.ErrorIs(t, err, errTimeout)
+		assert.Equal(t, []string{"/job/test-job/456/stop"}, recorder.paths())
+	})
+
+	t.Run("cancellation cancels queue item", func(t *testing.T) {
+		recorder := &abortRecorder{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				recorder.add(r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":123,"why":"Waiting for executor"}`))
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+		jenkins.AbortOnCancel = true
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err = jenkins.waitForCompletion(ctx, testJobName, 123, 20*time.Millisecond, time.Minute)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, []string{"/queue/cancelItem"}, recorder.paths())
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		recorder := &abortRecorder{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				recorder.add(r.URL.Path)
+			}
+			_, _ = w.Write([]byte(`{"id":123,"why":"Waiting for executor"}`))
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		_, err = jenkins.waitForCompletion(
+			context.Background(),
+			testJobName,
+			123,
+			20*time.Millisecond,
+			100*time.Millisecond,
+		)
+
+		assert.ErrorIs(t, err, errTimeout)
+		assert.Empty(t, recorder.paths())
+	})
+}
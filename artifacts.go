@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"crypto/md5" // #nosec G501 -- Jenkins fingerprints are MD5 checksums
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+type (
+	// Artifact represents a file archived by a Jenkins build
+	Artifact struct {
+		FileName     string `json:"fileName"`
+		RelativePath string `json:"relativePath"`
+	}
+
+	// Fingerprint represents the MD5 checksum Jenkins recorded for a file
+	Fingerprint struct {
+		FileName string `json:"fileName"`
+		Hash     string `json:"hash"`
+	}
+)
+
+// matchArtifacts returns the artifacts whose relative path or file name matches any pattern
+func matchArtifacts(artifacts []Artifact, patterns []string) ([]Artifact, error) {
+	var matched []Artifact
+	for _, artifact := range artifacts {
+		for _, pattern := range patterns {
+			byPath, err := path.Match(pattern, artifact.RelativePath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid artifact pattern %q: %w", pattern, err)
+			}
+			byName, _ := path.Match(pattern, artifact.FileName)
+			if byPath || byName {
+				matched = append(matched, artifact)
+				break
+			}
+		}
+	}
+
+	return matched, nil
+}
+
+// getFingerprints fetches the fingerprints recorded for a build, keyed by file name.
+// Jenkins only records the base name of fingerprinted files, so several distinct
+// hashes are returned when files in different directories share a name.
+func (jenkins *Jenkins) getFingerprints(
+	ctx context.Context,
+	job string,
+	buildNumber int,
+) (map[string][]string, error) {
+	path := fmt.Sprintf("%s/%d/api/json", jenkins.parseJobPath(job), buildNumber)
+	params := url.Values{"tree": []string{"fingerprint[fileName,hash]"}}
+
+	var build struct {
+		Fingerprint []Fingerprint `json:"fingerprint"`
+	}
+	if err := jenkins.get(ctx, path, params, &build); err != nil {
+		return nil, fmt.Errorf("failed to get fingerprints for %s #%d: %w", job, buildNumber, err)
+	}
+
+	hashes := make(map[string][]string, len(build.Fingerprint))
+	for _, fp := range build.Fingerprint {
+		if !slices.Contains(hashes[fp.FileName], fp.Hash) {
+			hashes[fp.FileName] = append(hashes[fp.FileName], fp.Hash)
+		}
+	}
+
+	return hashes, nil
+}
+
+// getStream performs a GET request and returns the response with an unread body.
+// The caller must close the response body.
+func (jenkins *Jenkins) getStream(ctx context.Context, path string) (*http.Response, error) {
+	resp, err := jenkins.doWithRetry(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", jenkins.buildURL(path, nil), nil)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	return resp, nil
+}
+
+// downloadArtifact streams a build artifact to dest and returns its MD5 checksum
+func (jenkins *Jenkins) downloadArtifact(
+	ctx context.Context,
+	job string,
+	buildNumber int,
+	artifact Artifact,
+	dest string,
+) (string, error) {
+	artifactPath := fmt.Sprintf(
+		"%s/%d/artifact/%s",
+		jenkins.parseJobPath(job),
+		buildNumber,
+		(&url.URL{Path: artifact.RelativePath}).EscapedPath(),
+	)
+
+	resp, err := jenkins.getStream(ctx, artifactPath)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so a failed download leaves no partial file behind
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := md5.New() // #nosec G401 -- used for integrity checks against Jenkins fingerprints
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// downloadArtifacts downloads the artifacts of a build matching the given patterns into dir.
+// When verify is set, each file is checked against the MD5 fingerprint recorded by Jenkins.
+func (jenkins *Jenkins) downloadArtifacts(
+	ctx context.Context,
+	job string,
+	build *BuildInfo,
+	patterns []string,
+	dir string,
+	verify bool,
+) error {
+	artifacts, err := matchArtifacts(build.Artifacts, patterns)
+	if err != nil {
+		return err
+	}
+	if len(artifacts) == 0 {
+		slog.Warn(
+			"no artifacts match the patterns",
+			"job", job,
+			"build_number", build.Number,
+			"patterns", patterns,
+		)
+		return nil
+	}
+
+	var fingerprints map[string][]string
+	names := make(map[string]int, len(build.Artifacts))
+	if verify {
+		if fingerprints, err = jenkins.getFingerprints(ctx, job, build.Number); err != nil {
+			return err
+		}
+		for _, artifact := range build.Artifacts {
+			names[artifact.FileName]++
+		}
+	}
+
+	for _, artifact := range artifacts {
+		// Refuse paths that would escape the target directory
+		if !filepath.IsLocal(filepath.FromSlash(artifact.RelativePath)) {
+			return fmt.Errorf(
+				"refusing to download artifact outside target directory: %s",
+				artifact.RelativePath,
+			)
+		}
+		dest := filepath.Join(dir, filepath.FromSlash(artifact.RelativePath))
+
+		sum, err := jenkins.downloadArtifact(ctx, job, build.Number, artifact, dest)
+		if err != nil {
+			return fmt.Errorf("failed to download artifact %s: %w", artifact.RelativePath, err)
+		}
+
+		if verify {
+			hashes := fingerprints[artifact.FileName]
+			switch {
+			case len(hashes) == 0:
+				slog.Warn(
+					"no fingerprint recorded for artifact, skipping verification",
+					"job", job,
+					"build_number", build.Number,
+					"artifact", artifact.RelativePath,
+				)
+			case len(hashes) > 1 || names[artifact.FileName] > 1:
+				// The fingerprint of another artifact with the same name could be checked instead
+				_ = os.Remove(dest)
+				return fmt.Errorf(
+					"artifact %s cannot be verified: several artifacts are named %s",
+					artifact.RelativePath,
+					artifact.FileName,
+				)
+			case hashes[0] != sum:
+				_ = os.Remove(dest)
+				return fmt.Errorf(
+					"artifact %s checksum mismatch: expected MD5 %s, got %s",
+					artifact.RelativePath,
+					hashes[0],
+					sum,
+				)
+			}
+		}
+
+		slog.Info(
+			"downloaded artifact",
+			"job", job,
+			"build_number", build.Number,
+			"artifact", artifact.RelativePath,
+			"path", dest,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// MD5 checksum of testArtifactContent
+const (
+	testArtifactContent = "package contents"
+	testArtifactMD5     = "9c72341d2c43306fc84cae343f2fc023"
+)
+
+func TestMatchArtifacts(t *testing.T) {
+	artifacts := []Artifact{
+		{FileName: "app.tar.gz", RelativePath: "dist/app.tar.gz"},
+		{FileName: "app.zip", RelativePath: "dist/app.zip"},
+		{FileName: "report.xml", RelativePath: "build/reports/report.xml"},
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{
+			name:     "file name pattern",
+			patterns: []string{"*.tar.gz"},
+			expected: []string{"dist/app.tar.gz"},
+		},
+		{
+			name:     "relative path pattern",
+			patterns: []string{"dist/*"},
+			expected: []string{"dist/app.tar.gz", "dist/app.zip"},
+		},
+		{
+			name:     "multiple patterns",
+			patterns: []string{"*.zip", "build/*/*.xml"},
+			expected: []string{"dist/app.zip", "build/reports/report.xml"},
+		},
+		{
+			name:     "no match",
+			patterns: []string{"*.jar"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := matchArtifacts(artifacts, tt.patterns)
+			assert.NoError(t, err)
+
+			var paths []string
+			for _, artifact := range matched {
+				paths = append(paths, artifact.RelativePath)
+			}
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := matchArtifacts(artifacts, []string{"[dist"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid artifact pattern")
+	})
+}
+
+// newTestArtifactServer serves a single artifact and its fingerprint
+func newTestArtifactServer(t *testing.T, fingerprint string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/test-job/456/artifact/dist/app.tar.gz":
+			_, _ = w.Write([]byte(testArtifactContent))
+		case testBuildStatusPath:
+			_, _ = w.Write([]byte(`{"fingerprint":[{"fileName":"app.tar.gz","hash":"` +
+				fingerprint + `"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDownloadArtifacts(t *testing.T) {
+	build := &BuildInfo{
+		Number: 456,
+		Artifacts: []Artifact{
+			{FileName: "app.tar.gz", RelativePath: "dist/app.tar.gz"},
+			{FileName: "app.zip", RelativePath: "dist/app.zip"},
+		},
+	}
+
+	t.Run("verified download", func(t *testing.T) {
+		server := newTestArtifactServer(t, testArtifactMD5)
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		dir := t.TempDir()
+		err = jenkins.downloadArtifacts(
+			context.Background(),
+			testJobName,
+			build,
+			[]string{"*.tar.gz"},
+			dir,
+			true,
+		)
+		assert.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(dir, "dist", "app.tar.gz"))
+		assert.NoError(t, err)
+		assert.Equal(t, testArtifactContent, string(data))
+		assert.NoFileExists(t, filepath.Join(dir, "dist", "app.zip"))
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		server := newTestArtifactServer(t, "00000000000000000000000000000000")
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		dir := t.TempDir()
+		err = jenkins.downloadArtifacts(
+			context.Background(),
+			testJobName,
+			build,
+			[]string{"*.tar.gz"},
+			dir,
+			true,
+		)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+		assert.NoFileExists(t, filepath.Join(dir, "dist", "app.tar.gz"))
+	})
+
+	t.Run("ambiguous file name", func(t *testing.T) {
+		server := newTestArtifactServer(t, testArtifactMD5)
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		ambiguous := &BuildInfo{
+			Number: 456,
+			Artifacts: []Artifact{
+				{FileName: "app.tar.gz", RelativePath: "dist/app.tar.gz"},
+				{FileName: "app.tar.gz", RelativePath: "legacy/app.tar.gz"},
+			},
+		}
+		dir := t.TempDir()
+		err = jenkins.downloadArtifacts(
+			context.Background(),
+			testJobName,
+			ambiguous,
+			[]string{"dist/*"},
+			dir,
+			true,
+		)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be verified: several artifacts are named app.tar.gz")
+		assert.NoFileExists(t, filepath.Join(dir, "dist", "app.tar.gz"))
+	})
+
+	t.Run("missing artifact", func(t *testing.T) {
+		server := newTestArtifactServer(t, testArtifactMD5)
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		err = jenkins.downloadArtifacts(
+			context.Background(),
+			testJobName,
+			build,
+			[]string{"*.zip"},
+			t.TempDir(),
+			false,
+		)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unexpected response code: 404")
+	})
+
+	t.Run("path outside target directory", func(t *testing.T) {
+		jenkins, err := NewJenkins(context.Background(), nil, testExampleURL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		escaping := &BuildInfo{
+			Number:    456,
+			Artifacts: []Artifact{{FileName: "passwd", RelativePath: "../../etc/passwd"}},
+		}
+		err = jenkins.downloadArtifacts(
+			context.Background(),
+			testJobName,
+			escaping,
+			[]string{"passwd"},
+			t.TempDir(),
+			false,
+		)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "outside target directory")
+	})
+}
+
+// TestExecDownloadArtifacts tests downloading artifacts after waiting for a job
+func TestExecDownloadArtifacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case testJobBuildPath:
+			w.Header().Set("Location", "http://jenkins.example.com/queue/item/123/")
+			w.WriteHeader(http.StatusCreated)
+		case testQueueItemPath:
+			_, _ = w.Write([]byte(`{"id":123,"executable":{"number":456}}`))
+		case testBuildStatusPath:
+			_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"SUCCESS",` +
+				`"artifacts":[{"fileName":"app.tar.gz","relativePath":"dist/app.tar.gz"}]}`))
+		case "/job/test-job/456/artifact/dist/app.tar.gz":
+			_, _ = w.Write([]byte(testArtifactContent))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	plugin := Plugin{
+		BaseURL:      server.URL,
+		Username:     testUserFoo,
+		Token:        testUserBar,
+		Job:          []string{testJobName},
+		Wait:         true,
+		PollInterval: 10 * time.Millisecond,
+		Artifacts:    []string{"*.tar.gz"},
+		ArtifactsDir: dir,
+	}
+
+	err := plugin.Exec(context.Background())
+
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "dist", "app.tar.gz"))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authentication modes
+const (
+	authBasic  = "basic"
+	authBearer = "bearer"
+	authOAuth2 = "oauth2"
+)
+
+const (
+	// tokenExpiryDelta is how long before its expiry an OAuth2 access token is refreshed
+	tokenExpiryDelta = 30 * time.Second
+
+	// maxTokenLifetime is how long an OAuth2 access token without expires_in is reused
+	maxTokenLifetime = 10 * time.Minute
+)
+
+type (
+	// Authenticator adds credentials other than basic auth to Jenkins requests
+	Authenticator interface {
+		Authenticate(req *http.Request) error
+	}
+
+	// bearerAuth sends a static bearer token
+	bearerAuth struct {
+		token string
+	}
+
+	// oauth2Auth sends an access token obtained with the OAuth2 client credentials grant,
+	// fetching a new one shortly before the cached token expires or after Jenkins rejected it
+	oauth2Auth struct {
+		client       *http.Client
+		tokenURL     string
+		clientID     string
+		clientSecret string
+		scopes       []string
+
+		mu          sync.Mutex
+		accessToken string
+		expiry      time.Time
+	}
+
+	// oauth2Token is the token endpoint response (RFC 6749, section 5.1)
+	oauth2Token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+)
+
+// parseAuthMode normalizes the authentication mode, defaulting to basic
+func parseAuthMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "", authBasic:
+		return authBasic, nil
+	case authBearer, authOAuth2:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown authentication mode %q (expected %s, %s or %s)",
+			mode,
+			authBasic,
+			authBearer,
+			authOAuth2,
+		)
+	}
+}
+
+// parseHeaders converts multi-line "Name: value" or "Name=value" pairs into request headers
+func parseHeaders(input string) (http.Header, error) {
+	headers := http.Header{}
+
+	for _, line := range strings.Split(input, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		idx := strings.IndexAny(line, ":=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid header %q (expected Name: value)", line)
+		}
+
+		name := strings.TrimSpace(line[:idx])
+		if strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		headers.Add(name, strings.TrimSpace(line[idx+1:]))
+	}
+
+	return headers, nil
+}
+
+// Authenticate implements Authenticator
+func (a *bearerAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// newOAuth2Auth creates an Authenticator using the OAuth2 client credentials grant,
+// requesting tokens from tokenURL with the given client
+func newOAuth2Auth(
+	client *http.Client,
+	tokenURL, clientID, clientSecret string,
+	scopes []string,
+) *oauth2Auth {
+	return &oauth2Auth{
+		client:       client,
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+	}
+}
+
+// Authenticate implements Authenticator
+func (a *oauth2Auth) Authenticate(req *http.Request) error {
+	token, err := a.token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// token returns the cached access token, fetching a new one if it is missing or about to expire
+func (a *oauth2Auth) token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.accessToken != "" && time.Now().Before(a.expiry) {
+		return a.accessToken, nil
+	}
+
+	token, err := a.fetchToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get OAuth2 access token: %w", err)
+	}
+
+	a.accessToken = token.AccessToken
+	a.expiry = time.Now().Add(maxTokenLifetime)
+	if token.ExpiresIn > 0 {
+		// Short-lived tokens are refreshed halfway, so their expiry never lies in the past
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		a.expiry = time.Now().Add(lifetime - min(tokenExpiryDelta, lifetime/2))
+	}
+
+	return a.accessToken, nil
+}
+
+// invalidate drops the cached access token if it is still the given rejected one,
+// so concurrent requests rejected with the same token fetch a new one only once
+func (a *oauth2Auth) invalidate(rejected string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.accessToken == rejected {
+		a.accessToken = ""
+	}
+}
+
+// renewToken drops the OAuth2 access token of a request Jenkins rejected with 401,
+// e.g. because the token was revoked, and reports whether the request should be
+// sent once more with a new token. The response is closed in that case.
+func (jenkins *Jenkins) renewToken(req *http.Request, resp *http.Response, err error) bool {
+	auth, ok := jenkins.Authenticator.(*oauth2Auth)
+	if !ok || err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+
+	slog.Warn(
+		"jenkins rejected the OAuth2 access token, fetching a new one",
+		"method", req.Method,
+		"path", req.URL.Path,
+	)
+	auth.invalidate(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+
+	return true
+}
+
+// fetchToken requests an access token from the token endpoint
+func (a *oauth2Auth) fetchToken(ctx context.Context) (*oauth2Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		a.tokenURL,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	var token oauth2Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token response has no access_token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return nil, fmt.Errorf("unsupported token type %q", token.TokenType)
+	}
+
+	return &token, nil
+}
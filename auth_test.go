@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAuthMode(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":         authBasic,
+		"basic":    authBasic,
+		"Bearer":   authBearer,
+		" oauth2 ": authOAuth2,
+	} {
+		mode, err := parseAuthMode(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+
+	_, err := parseAuthMode("digest")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown authentication mode "digest"`)
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := parseHeaders("X-Forwarded-User: drone\n\nx-team=platform\nX-Query: a=b")
+	assert.NoError(t, err)
+	assert.Equal(t, http.Header{
+		"X-Forwarded-User": {"drone"},
+		"X-Team":           {"platform"},
+		"X-Query":          {"a=b"},
+	}, headers)
+
+	_, err = parseHeaders("no separator")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid header "no separator"`)
+
+	_, err = parseHeaders("Bad Name: value")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid header name "Bad Name"`)
+}
+
+func TestValidateConfigAuthModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		plugin Plugin
+		errMsg string
+	}{
+		{name: "bearer", plugin: Plugin{AuthMode: authBearer, Token: testUserBar}},
+		{name: "bearer without token", plugin: Plugin{AuthMode: authBearer}, errMsg: "bearer mode needs a token"},
+		{
+			name: "oauth2",
+			plugin: Plugin{
+				AuthMode:      authOAuth2,
+				OAuthTokenURL: testExampleURL,
+				OAuthClientID: testUserFoo,
+				OAuthSecret:   testUserBar,
+			},
+		},
+		{
+			name:   "oauth2 without secret",
+			plugin: Plugin{AuthMode: authOAuth2, OAuthTokenURL: testExampleURL, OAuthClientID: testUserFoo},
+			errMsg: "oauth2 mode needs a token URL, client ID and secret",
+		},
+		{name: "unknown mode", plugin: Plugin{AuthMode: "digest"}, errMsg: "unknown authentication mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.BaseURL = testExampleURL
+			err := tt.plugin.validateConfig()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestOAuth2Auth(t *testing.T) {
+	var fetches int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+
+		clientID, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "drone", clientID)
+		assert.Equal(t, "s3cret", secret)
+		assert.Equal(t, "client_credentials", r.PostFormValue("grant_type"))
+		assert.Equal(t, "jenkins read", r.PostFormValue("scope"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access-123","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	auth := newOAuth2Auth(http.DefaultClient, tokenServer.URL, "drone", "s3cret", []string{"jenkins", "read"})
+
+	for range 2 {
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, testExampleURL, nil)
+		assert.NoError(t, auth.Authenticate(req))
+		assert.Equal(t, "Bearer access-123", req.Header.Get("Authorization"))
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// An expired token is refreshed
+	auth.expiry = time.Now().Add(-time.Second)
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, testExampleURL, nil)
+	assert.NoError(t, auth.Authenticate(req))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+	assert.True(t, auth.expiry.After(time.Now().Add(59*time.Minute-tokenExpiryDelta)))
+
+	t.Run("token without expiry", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"access_token":"access-456"}`))
+		}))
+		defer server.Close()
+
+		auth := newOAuth2Auth(http.DefaultClient, server.URL, "drone", "s3cret", nil)
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, testExampleURL, nil)
+		assert.NoError(t, auth.Authenticate(req))
+		assert.WithinDuration(t, time.Now().Add(maxTokenLifetime), auth.expiry, time.Minute)
+	})
+
+	t.Run("short-lived token", func(t *testing.T) {
+		var fetches int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&fetches, 1)
+			_, _ = w.Write([]byte(`{"access_token":"access-789","expires_in":20}`))
+		}))
+		defer server.Close()
+
+		auth := newOAuth2Auth(http.DefaultClient, server.URL, "drone", "s3cret", nil)
+		for range 2 {
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, testExampleURL, nil)
+			assert.NoError(t, auth.Authenticate(req))
+			assert.Equal(t, "Bearer access-789", req.Header.Get("Authorization"))
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+		assert.WithinDuration(t, time.Now().Add(10*time.Second), auth.expiry, 2*time.Second)
+	})
+
+	t.Run("token error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+		}))
+		defer server.Close()
+
+		auth := newOAuth2Auth(http.DefaultClient, server.URL, "drone", "wrong", nil)
+		req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, testExampleURL, nil)
+		err := auth.Authenticate(req)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get OAuth2 access token")
+		assert.Contains(t, err.Error(), "invalid_client")
+	})
+}
+
+// TestOAuth2TokenRejected tests fetching a new access token once when Jenkins rejects
+// the cached one, e.g. because it was revoked before it expired
+func TestOAuth2TokenRejected(t *testing.T) {
+	var fetches int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&fetches, 1)
+		_, _ = w.Write([]byte(`{"access_token":"access-` + strconv.Itoa(int(n)) + `","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	newServer := func(valid string) (*httptest.Server, *int32) {
+		var requests int32
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if r.Header.Get("Authorization") != "Bearer "+valid {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		})), &requests
+	}
+
+	t.Run("revoked token", func(t *testing.T) {
+		atomic.StoreInt32(&fetches, 0)
+		server, requests := newServer("access-2")
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+		jenkins.Authenticator = newOAuth2Auth(http.DefaultClient, tokenServer.URL, "drone", "s3cret", nil)
+
+		assert.NoError(t, jenkins.get(context.Background(), "/api/json", nil, nil))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+		assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+
+		// The new token is cached
+		assert.NoError(t, jenkins.get(context.Background(), "/api/json", nil, nil))
+		assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+	})
+
+	t.Run("rejected again", func(t *testing.T) {
+		atomic.StoreInt32(&fetches, 0)
+		server, requests := newServer("never")
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+		jenkins.Authenticator = newOAuth2Auth(http.DefaultClient, tokenServer.URL, "drone", "s3cret", nil)
+
+		err = jenkins.get(context.Background(), "/api/json", nil, nil)
+		var httpErr *HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+		assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+	})
+}
+
+// TestExecWithBearerAuthAndHeaders tests sending a bearer token and extra headers with every request
+func TestExecWithBearerAuthAndHeaders(t *testing.T) {
+	var crumbRequested bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer "+testUserBar, r.Header.Get("Authorization"))
+		assert.Equal(t, "drone", r.Header.Get("X-Forwarded-User"))
+
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			crumbRequested = true
+			_, _ = w.Write([]byte(`{"crumb":"abc","crumbRequestField":"Jenkins-Crumb"}`))
+			return
+		}
+
+		assert.Equal(t, "abc", r.Header.Get("Jenkins-Crumb"))
+		w.Header().Set("Location", "http://jenkins.example.com/queue/item/1/")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	plugin := Plugin{
+		BaseURL: server.URL,
+		// The username is ignored in bearer mode
+		Username: testUserFoo,
+		Token:    testUserBar,
+		AuthMode: authBearer,
+		Headers:  "X-Forwarded-User: drone\nAuthorization: Basic ignored",
+		Job:      []string{testJobName},
+	}
+
+	err := plugin.Exec(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, crumbRequested)
+}
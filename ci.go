@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// CI providers, detected from the variables each of them sets in every step
+const (
+	ciGitHub     = "github"
+	ciGitLab     = "gitlab"
+	ciWoodpecker = "woodpecker"
+	ciDrone      = "drone"
+)
+
+// defaultOutputFile is the dotenv file the results are written to on GitLab and
+// Woodpecker, which have no step output mechanism the plugin can write to directly
+const defaultOutputFile = "jenkins.env"
+
+// detectCI returns the CI provider running the plugin, or an empty string if unknown
+func detectCI() string {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return ciGitHub
+	case os.Getenv("GITLAB_CI") == "true":
+		return ciGitLab
+	case os.Getenv("CI") == ciWoodpecker:
+		// Checked before Drone, as Woodpecker may set DRONE_* variables for compatibility
+		return ciWoodpecker
+	case os.Getenv("DRONE") == "true":
+		return ciDrone
+	default:
+		return ""
+	}
+}
+
+// publishOutputs exposes the outcome of every job the way the CI provider expects:
+// GitHub Actions outputs and step summary, Drone outputs and card, and a dotenv file,
+// written to outputFile or by default on GitLab and Woodpecker
+func publishOutputs(summary *Summary, outputFile string) {
+	publishGitHub(summary)
+	publishDrone(summary)
+
+	provider := detectCI()
+	if outputFile == "" && (provider == ciGitLab || provider == ciWoodpecker) {
+		outputFile = defaultOutputFile
+	}
+	if outputFile == "" {
+		return
+	}
+
+	slog.Debug("writing output file", "provider", provider, "path", outputFile)
+	if err := writeOutputFile(outputFile, provider, summary); err != nil {
+		slog.Warn("failed to write output file", "path", outputFile, "error", err)
+	}
+}
+
+// envOutputs returns the JENKINS_* variables of every job, suffixed with the job name,
+// those of the last job without a suffix and all job records as JSON in JENKINS_RESULTS
+func envOutputs(summary *Summary) (map[string]string, error) {
+	results, err := json.Marshal(summary.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]string{"JENKINS_RESULTS": string(results)}
+	for i, job := range summary.Jobs {
+		suffixes := []string{"_" + envKey(job.Job)}
+		if i == len(summary.Jobs)-1 {
+			suffixes = append(suffixes, "")
+		}
+
+		for _, suffix := range suffixes {
+			outputs["JENKINS_STATUS"+suffix] = job.Status
+			outputs["JENKINS_RESULT"+suffix] = job.Result
+			outputs["JENKINS_BUILD_URL"+suffix] = job.URL
+			outputs["JENKINS_BUILD_NUMBER"+suffix] = ""
+			if job.BuildNumber > 0 {
+				outputs["JENKINS_BUILD_NUMBER"+suffix] = strconv.Itoa(job.BuildNumber)
+			}
+		}
+	}
+
+	return outputs, nil
+}
+
+// envKey converts a job name into an environment variable name suffix
+func envKey(job string) string {
+	return strings.ToUpper(strings.ReplaceAll(outputKey(job), "-", "_"))
+}
+
+// writeOutputFile writes the job variables to a dotenv file, in the restricted
+// format of GitLab dotenv reports when running in GitLab CI
+func writeOutputFile(path, provider string, summary *Summary) error {
+	outputs, err := envOutputs(summary)
+	if err != nil {
+		return err
+	}
+
+	content := ""
+	if provider == ciGitLab {
+		content = gitlabDotenv(outputs)
+	} else if content, err = godotenv.Marshal(outputs); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(content+"\n"), 0o600)
+}
+
+// gitlabDotenv formats variables as a GitLab dotenv report. Reports are limited in size
+// and support neither escapes nor whitespace in values, so the JSON records, empty values
+// and values containing whitespace are left out.
+func gitlabDotenv(vars map[string]string) string {
+	lines := make([]string, 0, len(vars))
+	for key, value := range vars {
+		if key == "JENKINS_RESULTS" || value == "" || strings.ContainsAny(value, " \t\r\n") {
+			continue
+		}
+		lines = append(lines, key+"="+value)
+	}
+	sort.Strings(lines)
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+// setCIEnv sets the variables used to detect the CI provider, clearing the others
+func setCIEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	for _, key := range []string{"GITHUB_ACTIONS", "GITLAB_CI", "CI", "DRONE"} {
+		t.Setenv(key, env[key])
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{name: "unknown", env: map[string]string{"CI": "true"}, expected: ""},
+		{name: "github", env: map[string]string{"CI": "true", "GITHUB_ACTIONS": "true"}, expected: ciGitHub},
+		{name: "gitlab", env: map[string]string{"CI": "true", "GITLAB_CI": "true"}, expected: ciGitLab},
+		{name: "woodpecker", env: map[string]string{"CI": "woodpecker", "DRONE": "true"}, expected: ciWoodpecker},
+		{name: "drone", env: map[string]string{"CI": "true", "DRONE": "true"}, expected: ciDrone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setCIEnv(t, tt.env)
+			assert.Equal(t, tt.expected, detectCI())
+		})
+	}
+}
+
+func TestEnvOutputs(t *testing.T) {
+	summary := &Summary{Jobs: []JobSummary{
+		{
+			Job:         "folder/build",
+			Status:      nodeSucceeded,
+			BuildNumber: 7,
+			URL:         testExampleURL + "/job/folder/job/build/7/",
+			Result:      resultSuccess,
+		},
+		{Job: "deploy-prod", Status: nodeFailed, Error: "failed to trigger job"},
+	}}
+
+	outputs, err := envOutputs(summary)
+	assert.NoError(t, err)
+	assert.Equal(t, resultSuccess, outputs["JENKINS_RESULT_FOLDER_BUILD"])
+	assert.Equal(t, "7", outputs["JENKINS_BUILD_NUMBER_FOLDER_BUILD"])
+	assert.Equal(t, testExampleURL+"/job/folder/job/build/7/", outputs["JENKINS_BUILD_URL_FOLDER_BUILD"])
+	assert.Equal(t, "failed", outputs["JENKINS_STATUS_DEPLOY_PROD"])
+
+	// The variables without a suffix describe the last job
+	assert.Equal(t, "failed", outputs["JENKINS_STATUS"])
+	assert.Equal(t, "", outputs["JENKINS_BUILD_NUMBER"])
+
+	var results []JobSummary
+	assert.NoError(t, json.Unmarshal([]byte(outputs["JENKINS_RESULTS"]), &results))
+	assert.Equal(t, summary.Jobs, results)
+}
+
+func TestGitLabDotenv(t *testing.T) {
+	content := gitlabDotenv(map[string]string{
+		"JENKINS_RESULTS":      `[{"job":"build"}]`,
+		"JENKINS_STATUS":       "succeeded",
+		"JENKINS_BUILD_URL":    testExampleURL + "/job/build/1/",
+		"JENKINS_BUILD_NUMBER": "",
+		"JENKINS_ERROR":        "with spaces",
+	})
+
+	assert.Equal(t,
+		"JENKINS_BUILD_URL="+testExampleURL+"/job/build/1/\n"+
+			"JENKINS_STATUS=succeeded",
+		content,
+	)
+}
+
+// TestExecOutputFile tests writing the results to a dotenv file on GitLab and Woodpecker
+func TestExecOutputFile(t *testing.T) {
+	newPlugin := func(t *testing.T) Plugin {
+		t.Helper()
+
+		tj := newTestJenkins(t, map[string]string{"deploy-prod": "SUCCESS"}, 1)
+		return Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"deploy-prod"},
+			Parameters:   `{"VERSION": "1.2.3", "TARGETS": ["eu", "us"]}`,
+			Wait:         true,
+			PollInterval: 10 * time.Millisecond,
+		}
+	}
+
+	t.Run("gitlab dotenv report", func(t *testing.T) {
+		setCIEnv(t, map[string]string{"CI": "true", "GITLAB_CI": "true"})
+		t.Chdir(t.TempDir())
+
+		plugin := newPlugin(t)
+		assert.NoError(t, plugin.Exec(context.Background()))
+
+		data, err := os.ReadFile(defaultOutputFile)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "JENKINS_RESULT=SUCCESS\n")
+		assert.Contains(t, string(data), "JENKINS_BUILD_NUMBER_DEPLOY_PROD=1\n")
+		assert.Contains(t, string(data), "JENKINS_BUILD_URL="+plugin.BaseURL+"/job/deploy-prod/1/\n")
+		assert.NotContains(t, string(data), "JENKINS_RESULTS")
+		assert.NotContains(t, string(data), `"`)
+	})
+
+	t.Run("woodpecker", func(t *testing.T) {
+		setCIEnv(t, map[string]string{"CI": "woodpecker"})
+		path := filepath.Join(t.TempDir(), "results.env")
+
+		plugin := newPlugin(t)
+		plugin.OutputFile = path
+		assert.NoError(t, plugin.Exec(context.Background()))
+
+		env, err := godotenv.Read(path)
+		assert.NoError(t, err)
+		assert.Equal(t, resultSuccess, env["JENKINS_RESULT_DEPLOY_PROD"])
+		assert.Equal(t, "succeeded", env["JENKINS_STATUS"])
+
+		var results []JobSummary
+		assert.NoError(t, json.Unmarshal([]byte(env["JENKINS_RESULTS"]), &results))
+		assert.Equal(t, "deploy-prod", results[0].Job)
+	})
+
+	t.Run("not written elsewhere by default", func(t *testing.T) {
+		setCIEnv(t, map[string]string{"CI": "true", "DRONE": "true"})
+		t.Chdir(t.TempDir())
+
+		plugin := newPlugin(t)
+		assert.NoError(t, plugin.Exec(context.Background()))
+
+		_, err := os.Stat(defaultOutputFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
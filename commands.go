@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// commands returns the subcommands for working with existing builds.
+// They share the global connection, authentication and TLS flags with the trigger mode.
+func commands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "status",
+			Usage:     "show the status of a build (default: the last build)",
+			ArgsUsage: "<job> [build]",
+			Action:    statusCommand,
+		},
+		{
+			Name:      "logs",
+			Usage:     "print the console log of a build",
+			ArgsUsage: "<job> <build>",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "follow",
+					Aliases: []string{"f"},
+					Usage:   "keep streaming the log until the build completes",
+				},
+			},
+			Action: logsCommand,
+		},
+		{
+			Name:      "abort",
+			Usage:     "stop a running build",
+			ArgsUsage: "<job> <build>",
+			Action:    abortCommand,
+		},
+		{
+			Name:      "wait",
+			Usage:     "wait for a queued or running build to complete",
+			ArgsUsage: "<job> <build> | --queue-id N [job]",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "queue-id",
+					Usage: "queue item ID returned when the build was triggered",
+				},
+			},
+			Action: waitCommand,
+		},
+	}
+}
+
+// newCommandClient creates a Jenkins client from the global flags
+func newCommandClient(c *cli.Context) (*Jenkins, error) {
+	if c.String("host") == "" {
+		return nil, errors.New("host is required")
+	}
+
+	plugin := Plugin{
+		BaseURL:          c.String("host"),
+		Username:         c.String("user"),
+		Token:            c.String(tokenParam),
+		RemoteToken:      c.String("remote-token"),
+		AuthMode:         c.String("auth-mode"),
+		OAuthTokenURL:    c.String("oauth-token-url"),
+		OAuthClientID:    c.String("oauth-client-id"),
+		OAuthSecret:      c.String("oauth-client-secret"),
+		OAuthScopes:      c.StringSlice("oauth-scopes"),
+		Headers:          c.String("headers"),
+		Insecure:         c.Bool("insecure"),
+		CACert:           c.String("ca-cert"),
+		ClientCert:       c.String("client-cert"),
+		ClientKey:        c.String("client-key"),
+		KeyPassphrase:    c.String("client-key-passphrase"),
+		Proxy:            c.String("proxy"),
+		RetryAttempts:    c.Int("retry-attempts"),
+		RetryBackoff:     c.Duration("retry-backoff"),
+		RetryMaxBackoff:  c.Duration("retry-max-backoff"),
+		RetryStatusCodes: c.IntSlice("retry-status-codes"),
+		Debug:            c.Bool("debug"),
+	}
+
+	jenkins, err := plugin.newJenkins(c.Context)
+	if err != nil {
+		return nil, err
+	}
+	jenkins.console = c.App.Writer
+
+	return jenkins, nil
+}
+
+// buildArgs parses the <job> <build> arguments of a subcommand.
+// The build number is optional when allowLast is set and defaults to zero.
+func buildArgs(c *cli.Context, allowLast bool) (string, int, error) {
+	job := strings.TrimSpace(c.Args().Get(0))
+	if job == "" {
+		return "", 0, errors.New("job name is required")
+	}
+
+	if c.NArg() < 2 {
+		if allowLast {
+			return job, 0, nil
+		}
+		return "", 0, errors.New("build number is required")
+	}
+
+	buildNumber, err := strconv.Atoi(c.Args().Get(1))
+	if err != nil || buildNumber <= 0 {
+		return "", 0, fmt.Errorf("invalid build number %q", c.Args().Get(1))
+	}
+
+	return job, buildNumber, nil
+}
+
+// jobFromURL converts a Jenkins job URL such as http://host/job/folder/job/name/
+// into the job name folder/name
+func jobFromURL(jobURL string) string {
+	u, err := url.Parse(jobURL)
+	if err != nil {
+		return ""
+	}
+
+	var names []string
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "job" {
+			name, err := url.PathUnescape(parts[i+1])
+			if err != nil {
+				return ""
+			}
+			names = append(names, name)
+			i++
+		}
+	}
+
+	return strings.Join(names, "/")
+}
+
+func statusCommand(c *cli.Context) error {
+	job, buildNumber, err := buildArgs(c, true)
+	if err != nil {
+		return err
+	}
+
+	jenkins, err := newCommandClient(c)
+	if err != nil {
+		return err
+	}
+
+	if buildNumber == 0 {
+		if buildNumber, err = jenkins.getLastBuildNumber(c.Context, job); err != nil {
+			return err
+		}
+	}
+
+	buildInfo, err := jenkins.getBuildInfo(c.Context, job, buildNumber)
+	if err != nil {
+		return err
+	}
+
+	status := buildInfo.Result
+	if buildInfo.Building {
+		status = "BUILDING"
+	}
+	duration := time.Duration(buildInfo.Duration) * time.Millisecond
+	if buildInfo.Building && buildInfo.Timestamp > 0 {
+		duration = time.Since(time.UnixMilli(buildInfo.Timestamp)).Round(time.Second)
+	}
+
+	_, _ = fmt.Fprintf(c.App.Writer, "job:      %s\n", job)
+	_, _ = fmt.Fprintf(c.App.Writer, "build:    #%d\n", buildInfo.Number)
+	_, _ = fmt.Fprintf(c.App.Writer, "status:   %s\n", status)
+	_, _ = fmt.Fprintf(c.App.Writer, "duration: %s\n", duration)
+	if buildInfo.URL != "" {
+		_, _ = fmt.Fprintf(c.App.Writer, "url:      %s\n", buildInfo.URL)
+	}
+
+	return nil
+}
+
+func logsCommand(c *cli.Context) error {
+	job, buildNumber, err := buildArgs(c, false)
+	if err != nil {
+		return err
+	}
+
+	jenkins, err := newCommandClient(c)
+	if err != nil {
+		return err
+	}
+
+	follower := jenkins.newConsoleFollower(job, buildNumber)
+	if !c.Bool("follow") {
+		return follower.drain(c.Context)
+	}
+
+	for {
+		more, err := follower.poll(c.Context)
+		if err != nil {
+			return err
+		}
+		if !more {
+			follower.flush()
+			return nil
+		}
+
+		if err := sleepContext(c.Context, c.Duration("poll-interval")); err != nil {
+			return err
+		}
+	}
+}
+
+func abortCommand(c *cli.Context) error {
+	job, buildNumber, err := buildArgs(c, false)
+	if err != nil {
+		return err
+	}
+
+	jenkins, err := newCommandClient(c)
+	if err != nil {
+		return err
+	}
+
+	return jenkins.stopBuild(c.Context, job, buildNumber)
+}
+
+func waitCommand(c *cli.Context) error {
+	policy, err := newResultPolicy(c.StringSlice("accept-results"), c.String("job-accept-results"))
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	jenkins, err := newCommandClient(c)
+	if err != nil {
+		return err
+	}
+	jenkins.FollowLog = c.Bool("follow-log")
+	jenkins.StageProgress = c.Bool("stage-progress")
+
+	pollInterval, timeout := c.Duration("poll-interval"), c.Duration("timeout")
+
+	var job string
+	var buildInfo *BuildInfo
+	if queueID := c.Int("queue-id"); queueID > 0 {
+		job = strings.TrimSpace(c.Args().Get(0))
+		if job == "" {
+			// Resolve the job from the queue item
+			queueItem, err := jenkins.getQueueItem(c.Context, queueID)
+			if err != nil {
+				return err
+			}
+			if queueItem.Task != nil {
+				job = jobFromURL(queueItem.Task.URL)
+			}
+			if job == "" {
+				return fmt.Errorf("failed to determine the job of queue item %d", queueID)
+			}
+		}
+		buildInfo, err = jenkins.waitForCompletion(c.Context, job, queueID, pollInterval, timeout)
+	} else {
+		var buildNumber int
+		if job, buildNumber, err = buildArgs(c, false); err != nil {
+			return err
+		}
+		buildInfo, err = jenkins.waitForBuild(c.Context, job, buildNumber, pollInterval, timeout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !policy.accepts(job, buildInfo.Result) {
+		err = &ResultError{
+			Job:    job,
+			Number: buildInfo.Number,
+			Result: buildInfo.Result,
+		}
+	}
+
+	summary := newSummaryRecorder([]string{job})
+	summary.record(job, c.Int("queue-id"), buildInfo, nil, err)
+	publishOutputs(summary.summary(), c.String("output-file"))
+
+	return err
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+// runTestCommand runs a subcommand against the given Jenkins server and returns its output
+func runTestCommand(t *testing.T, serverURL string, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	app := &cli.App{
+		Writer: &out,
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "host"},
+			&cli.StringFlag{Name: "user"},
+			&cli.StringFlag{Name: tokenParam},
+			&cli.DurationFlag{Name: "poll-interval", Value: 10 * time.Millisecond},
+			&cli.DurationFlag{Name: "timeout", Value: 5 * time.Second},
+			&cli.StringSliceFlag{Name: "accept-results", Value: cli.NewStringSlice(resultSuccess)},
+			&cli.StringFlag{Name: "output-file"},
+		},
+		Commands: commands(),
+	}
+
+	argv := append([]string{"drone-jenkins", "--host", serverURL}, args...)
+	err := app.RunContext(context.Background(), argv)
+
+	return out.String(), err
+}
+
+func TestJobFromURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "http://jenkins.example.com/job/deploy/", expected: "deploy"},
+		{url: "http://jenkins.example.com/job/folder/job/deploy/", expected: "folder/deploy"},
+		{url: "http://jenkins.example.com/jenkins/job/my%20job/", expected: "my job"},
+		{url: "http://jenkins.example.com/queue/item/1/", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.expected, jobFromURL(tt.url))
+		})
+	}
+}
+
+func TestStatusCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/test-job/api/json":
+			assert.Equal(t, "lastBuild[number]", r.URL.Query().Get("tree"))
+			_, _ = w.Write([]byte(`{"lastBuild":{"number":456}}`))
+		case testBuildStatusPath:
+			_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"FAILURE",` +
+				`"duration":61000,"url":"http://jenkins.example.com/job/test-job/456/"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	expected := "job:      test-job\n" +
+		"build:    #456\n" +
+		"status:   FAILURE\n" +
+		"duration: 1m1s\n" +
+		"url:      http://jenkins.example.com/job/test-job/456/\n"
+
+	t.Run("last build", func(t *testing.T) {
+		out, err := runTestCommand(t, server.URL, "status", testJobName)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, out)
+	})
+
+	t.Run("given build", func(t *testing.T) {
+		out, err := runTestCommand(t, server.URL, "status", testJobName, "456")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, out)
+	})
+
+	t.Run("invalid build number", func(t *testing.T) {
+		_, err := runTestCommand(t, server.URL, "status", testJobName, "latest")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid build number "latest"`)
+	})
+
+	t.Run("missing job", func(t *testing.T) {
+		_, err := runTestCommand(t, server.URL, "status")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "job name is required")
+	})
+}
+
+func TestLogsCommand(t *testing.T) {
+	server := newTestConsoleServer(t, []string{"Started\n", "Finished: SUCCESS\n"})
+	defer server.Close()
+
+	t.Run("full log", func(t *testing.T) {
+		out, err := runTestCommand(t, server.URL, "logs", testJobName, "456")
+		assert.NoError(t, err)
+		assert.Equal(t, "Started\nFinished: SUCCESS\n", out)
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		out, err := runTestCommand(t, server.URL, "logs", "--follow", testJobName, "456")
+		assert.NoError(t, err)
+		assert.Equal(t, "Started\nFinished: SUCCESS\n", out)
+	})
+
+	t.Run("missing build", func(t *testing.T) {
+		_, err := runTestCommand(t, server.URL, "logs", testJobName)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "build number is required")
+	})
+}
+
+func TestAbortCommand(t *testing.T) {
+	var stopped int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/test-job/456/stop":
+			assert.Equal(t, http.MethodPost, r.Method)
+			atomic.StoreInt32(&stopped, 1)
+		case testBuildStatusPath:
+			_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"ABORTED"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, err := runTestCommand(t, server.URL, "abort", testJobName, "456")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+}
+
+func TestWaitCommand(t *testing.T) {
+	newServer := func(result string) *httptest.Server {
+		var buildCalls int32
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case testQueueItemPath:
+				_, _ = w.Write([]byte(`{"id":123,"executable":{"number":456},` +
+					`"task":{"name":"test-job","url":"http://jenkins.example.com/job/test-job/"}}`))
+			case testBuildStatusPath:
+				if atomic.AddInt32(&buildCalls, 1) == 1 {
+					_, _ = w.Write([]byte(`{"number":456,"building":true}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"` + result + `"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+
+	t.Run("build number", func(t *testing.T) {
+		server := newServer(resultSuccess)
+		defer server.Close()
+
+		_, err := runTestCommand(t, server.URL, "wait", testJobName, "456")
+		assert.NoError(t, err)
+	})
+
+	t.Run("queue id resolves job", func(t *testing.T) {
+		server := newServer(resultSuccess)
+		defer server.Close()
+
+		_, err := runTestCommand(t, server.URL, "wait", "--queue-id", "123")
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejected result", func(t *testing.T) {
+		server := newServer(resultUnstable)
+		defer server.Close()
+
+		_, err := runTestCommand(t, server.URL, "wait", testJobName, "456")
+		assert.Error(t, err)
+		assert.Equal(t, exitUnstable, exitCode(err))
+	})
+
+	t.Run("github output", func(t *testing.T) {
+		server := newServer(resultUnstable)
+		defer server.Close()
+
+		output := filepath.Join(t.TempDir(), "github_output")
+		assert.NoError(t, os.WriteFile(output, nil, 0o600))
+		t.Setenv("GITHUB_OUTPUT", output)
+
+		_, err := runTestCommand(t, server.URL, "wait", testJobName, "456")
+		assert.Error(t, err)
+
+		data, err := os.ReadFile(output)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "result=UNSTABLE\n")
+		assert.Contains(t, string(data), "status_test-job=failed\n")
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		server := newServer(resultSuccess)
+		defer server.Close()
+
+		setCIEnv(t, map[string]string{"CI": "woodpecker"})
+		path := filepath.Join(t.TempDir(), "results.env")
+
+		_, err := runTestCommand(t, server.URL, "--output-file", path, "wait", testJobName, "456")
+		assert.NoError(t, err)
+
+		env, err := godotenv.Read(path)
+		assert.NoError(t, err)
+		assert.Equal(t, resultSuccess, env["JENKINS_RESULT"])
+		assert.Equal(t, "456", env["JENKINS_BUILD_NUMBER"])
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxDrainRequests bounds the number of progressive log requests made after a build finishes
+	maxDrainRequests = 100
+
+	// drainInterval is the pause between progressive log requests made after a build finishes
+	drainInterval = 200 * time.Millisecond
+)
+
+// consoleFollower incrementally streams the console log of a single build
+// using the Jenkins logText/progressiveText endpoint.
+type consoleFollower struct {
+	jenkins     *Jenkins
+	job         string
+	buildNumber int
+	offset      int64         // Byte offset for the next request (X-Text-Size)
+	partial     []byte        // Trailing text not yet terminated by a newline
+	interval    time.Duration // Pause between requests while draining
+}
+
+// newConsoleFollower creates a follower for the given build starting at offset zero
+func (jenkins *Jenkins) newConsoleFollower(job string, buildNumber int) *consoleFollower {
+	return &consoleFollower{
+		jenkins:     jenkins,
+		job:         job,
+		buildNumber: buildNumber,
+		interval:    drainInterval,
+	}
+}
+
+// getConsoleText fetches the console log of a build starting at the given byte offset.
+// It returns the log text, the offset to use for the next request,
+// and whether Jenkins reports that more data will be written.
+func (jenkins *Jenkins) getConsoleText(
+	ctx context.Context,
+	job string,
+	buildNumber int,
+	start int64,
+) ([]byte, int64, bool, error) {
+	path := fmt.Sprintf("%s/%d/logText/progressiveText", jenkins.parseJobPath(job), buildNumber)
+	params := url.Values{"start": []string{strconv.FormatInt(start, 10)}}
+
+	data, header, err := jenkins.getRaw(ctx, path, params)
+	if err != nil {
+		return nil, start, false, fmt.Errorf(
+			"failed to get console log for %s #%d: %w",
+			job,
+			buildNumber,
+			err,
+		)
+	}
+
+	next := start + int64(len(data))
+	if size := header.Get("X-Text-Size"); size != "" {
+		if n, err := strconv.ParseInt(size, 10, 64); err == nil {
+			next = n
+		}
+	}
+
+	return data, next, header.Get("X-More-Data") == "true", nil
+}
+
+// poll fetches any new console output and writes complete lines.
+// It returns whether Jenkins reports more data to come.
+func (f *consoleFollower) poll(ctx context.Context) (bool, error) {
+	data, next, more, err := f.jenkins.getConsoleText(ctx, f.job, f.buildNumber, f.offset)
+	if err != nil {
+		return false, err
+	}
+
+	f.offset = next
+	f.write(data)
+
+	return more, nil
+}
+
+// drain fetches the remaining console output after the build has finished
+// and flushes any unterminated last line. The output is cut off with a warning
+// after maxDrainRequests requests, which are spaced drainInterval apart.
+func (f *consoleFollower) drain(ctx context.Context) error {
+	defer f.flush()
+
+	for i := 0; i < maxDrainRequests; i++ {
+		if i > 0 {
+			if err := sleepContext(ctx, f.interval); err != nil {
+				return err
+			}
+		}
+
+		more, err := f.poll(ctx)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+
+	slog.Warn(
+		"console log truncated, more output is available in Jenkins",
+		"job", f.job,
+		"build_number", f.buildNumber,
+		"offset", f.offset,
+		"requests", maxDrainRequests,
+	)
+
+	return nil
+}
+
+// write outputs every complete line in data and buffers the remainder
+func (f *consoleFollower) write(data []byte) {
+	f.partial = append(f.partial, data...)
+
+	idx := bytes.LastIndexByte(f.partial, '\n')
+	if idx == -1 {
+		return
+	}
+
+	lines := f.partial[:idx]
+	f.partial = append([]byte(nil), f.partial[idx+1:]...)
+	f.jenkins.writeConsole(f.job, lines)
+}
+
+// flush outputs the buffered unterminated line, if any
+func (f *consoleFollower) flush() {
+	if len(f.partial) == 0 {
+		return
+	}
+
+	f.jenkins.writeConsole(f.job, f.partial)
+	f.partial = nil
+}
+
+// writeConsole writes newline separated console lines, prefixed with the job name if enabled
+func (jenkins *Jenkins) writeConsole(job string, lines []byte) {
+	var buf bytes.Buffer
+	for _, line := range bytes.Split(lines, []byte("\n")) {
+		if jenkins.LogPrefix {
+			buf.WriteString("[" + job + "] ")
+		}
+		buf.Write(bytes.TrimSuffix(line, []byte("\r")))
+		buf.WriteByte('\n')
+	}
+
+	jenkins.consoleMu.Lock()
+	defer jenkins.consoleMu.Unlock()
+	_, _ = jenkins.console.Write(buf.Bytes())
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConsolePath = "/job/test-job/456/logText/progressiveText"
+
+// newTestConsoleServer serves the given console chunks, one per request,
+// reporting more data until the last chunk has been returned.
+func newTestConsoleServer(t *testing.T, chunks []string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, testConsolePath, r.URL.Path)
+
+		start, err := strconv.Atoi(r.URL.Query().Get("start"))
+		assert.NoError(t, err)
+
+		// Find the chunk that begins at the requested offset
+		offset := 0
+		for i, chunk := range chunks {
+			if offset == start {
+				w.Header().Set("X-Text-Size", strconv.Itoa(offset+len(chunk)))
+				if i < len(chunks)-1 {
+					w.Header().Set("X-More-Data", "true")
+				}
+				_, _ = w.Write([]byte(chunk))
+				return
+			}
+			offset += len(chunk)
+		}
+
+		w.Header().Set("X-Text-Size", strconv.Itoa(start))
+	}))
+}
+
+func TestGetConsoleText(t *testing.T) {
+	server := newTestConsoleServer(t, []string{"Started\n", "Finished: SUCCESS\n"})
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+
+	data, next, more, err := jenkins.getConsoleText(context.Background(), testJobName, 456, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "Started\n", string(data))
+	assert.Equal(t, int64(8), next)
+	assert.True(t, more)
+
+	data, next, more, err = jenkins.getConsoleText(context.Background(), testJobName, 456, next)
+	assert.NoError(t, err)
+	assert.Equal(t, "Finished: SUCCESS\n", string(data))
+	assert.Equal(t, int64(26), next)
+	assert.False(t, more)
+}
+
+func TestConsoleFollower(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		prefix   bool
+		expected string
+	}{
+		{
+			name:     "complete lines",
+			chunks:   []string{"line1\nline2\n", "line3\n"},
+			expected: "line1\nline2\nline3\n",
+		},
+		{
+			name:     "line split across chunks",
+			chunks:   []string{"hel", "lo\nwor", "ld"},
+			expected: "hello\nworld\n",
+		},
+		{
+			name:     "carriage returns are stripped",
+			chunks:   []string{"windows\r\n"},
+			expected: "windows\n",
+		},
+		{
+			name:     "job prefix",
+			chunks:   []string{"line1\n", "line2\n"},
+			prefix:   true,
+			expected: "[test-job] line1\n[test-job] line2\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestConsoleServer(t, tt.chunks)
+			defer server.Close()
+
+			jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+			assert.NoError(t, err)
+
+			var out bytes.Buffer
+			jenkins.console = &out
+			jenkins.LogPrefix = tt.prefix
+
+			follower := jenkins.newConsoleFollower(testJobName, 456)
+			follower.interval = time.Millisecond
+			assert.NoError(t, follower.drain(context.Background()))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+// TestConsoleFollowerTruncated tests that a log still growing after maxDrainRequests is cut off with a warning
+func TestConsoleFollowerTruncated(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		w.Header().Set("X-Text-Size", strconv.Itoa(int(n)*5))
+		w.Header().Set("X-More-Data", "true")
+		_, _ = w.Write([]byte("line\n"))
+	}))
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+	jenkins.console = &bytes.Buffer{}
+	out := captureLog(t)
+
+	follower := jenkins.newConsoleFollower(testJobName, 456)
+	follower.interval = time.Millisecond
+	start := time.Now()
+	assert.NoError(t, follower.drain(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), (maxDrainRequests-1)*time.Millisecond)
+	assert.Equal(t, int32(maxDrainRequests), atomic.LoadInt32(&requests))
+	assert.Equal(t,
+		"level=WARN msg=\"console log truncated, more output is available in Jenkins\" "+
+			"job=test-job build_number=456 offset=500 requests=100\n",
+		out.String(),
+	)
+}
+
+func TestWaitForCompletionFollowLog(t *testing.T) {
+	var buildCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case testQueueItemPath:
+			_, _ = w.Write([]byte(`{"id":123,"executable":{"number":456}}`))
+		case testBuildStatusPath:
+			if atomic.AddInt32(&buildCalls, 1) == 1 {
+				_, _ = w.Write([]byte(`{"number":456,"building":true,"result":null}`))
+			} else {
+				_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"SUCCESS"}`))
+			}
+		case testConsolePath:
+			// The final console line only appears once the build has finished
+			start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+			text := "Building\nFinished: SUCCESS\n"
+			if atomic.LoadInt32(&buildCalls) < 2 {
+				text = "Building\n"
+			} else if start < len(text) {
+				w.Header().Set("X-More-Data", "true")
+			}
+			w.Header().Set("X-Text-Size", strconv.Itoa(len(text)))
+			if start < len(text) {
+				_, _ = w.Write([]byte(text[start:]))
+			}
+		}
+	}))
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	jenkins.console = &out
+	jenkins.FollowLog = true
+
+	buildInfo, err := jenkins.waitForCompletion(
+		context.Background(),
+		testJobName,
+		123,
+		50*time.Millisecond,
+		5*time.Second,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "SUCCESS", buildInfo.Result)
+	assert.Equal(t, "Building\nFinished: SUCCESS\n", out.String())
+}
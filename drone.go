@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// cardSchema is the adaptive card template rendering the card data in the Drone UI
+const cardSchema = "https://raw.githubusercontent.com/appleboy/drone-jenkins/master/card.json"
+
+type (
+	// droneCard is the content of the card file, the template URL and its data
+	droneCard struct {
+		Schema string   `json:"schema"`
+		Data   cardData `json:"data"`
+	}
+
+	// cardData lists the jobs shown on the card
+	cardData struct {
+		Jobs []cardJob `json:"jobs"`
+	}
+
+	// cardJob is a job on the card, with display-ready values
+	cardJob struct {
+		Job      string `json:"job"`
+		Status   string `json:"status"`
+		Build    string `json:"build"` // Markdown link to the build, "-" without a build
+		URL      string `json:"url,omitempty"`
+		Duration string `json:"duration"`
+	}
+)
+
+// publishDrone exposes the outcome of every job as Drone step outputs and
+// a card, when running in Drone
+func publishDrone(summary *Summary) {
+	if path := os.Getenv("DRONE_OUTPUT"); path != "" {
+		outputs, err := envOutputs(summary)
+		if err == nil {
+			err = appendDotenv(path, outputs)
+		}
+		if err != nil {
+			slog.Warn("failed to write Drone output", "error", err)
+		}
+	}
+
+	if path := os.Getenv("DRONE_CARD_PATH"); path != "" {
+		if err := writeCard(path, summary); err != nil {
+			slog.Warn("failed to write Drone card", "error", err)
+		}
+	}
+}
+
+// appendDotenv appends variables to a dotenv file, creating it if needed
+func appendDotenv(path string, vars map[string]string) error {
+	content, err := godotenv.Marshal(vars)
+	if err != nil {
+		return err
+	}
+
+	return appendFile(path, content+"\n")
+}
+
+// writeCard writes the card file. Cards written to stdout or stderr are
+// wrapped in the escape sequence the Drone runner extracts from the log.
+func writeCard(path string, summary *Summary) error {
+	card := droneCard{Schema: cardSchema, Data: cardData{Jobs: make([]cardJob, 0, len(summary.Jobs))}}
+	for _, job := range summary.Jobs {
+		card.Data.Jobs = append(card.Data.Jobs, cardJob{
+			Job:      job.Job,
+			Status:   job.displayResult(),
+			Build:    job.buildLink(),
+			URL:      job.URL,
+			Duration: job.duration(),
+		})
+	}
+
+	data, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+
+	switch path {
+	case "/dev/stdout":
+		return writeCardTo(os.Stdout, data)
+	case "/dev/stderr":
+		return writeCardTo(os.Stderr, data)
+	default:
+		return os.WriteFile(path, data, 0o600)
+	}
+}
+
+// writeCardTo writes the card encoded in the escape sequence of the Drone runner
+func writeCardTo(w io.Writer, data []byte) error {
+	_, err := io.WriteString(w, "\u001B]1338;"+base64.StdEncoding.EncodeToString(data)+"\u001B]0m\n")
+	return err
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCard(t *testing.T) {
+	summary := &Summary{Jobs: []JobSummary{
+		{
+			Job:         "build",
+			Status:      nodeSucceeded,
+			BuildNumber: 42,
+			URL:         testExampleURL + "/job/build/42/",
+			Result:      resultSuccess,
+			DurationMs:  83512,
+		},
+		{Job: "deploy", Status: jobNotRun},
+	}}
+	expected := droneCard{Schema: cardSchema, Data: cardData{Jobs: []cardJob{
+		{
+			Job:      "build",
+			Status:   resultSuccess,
+			Build:    "[#42](" + testExampleURL + "/job/build/42/)",
+			URL:      testExampleURL + "/job/build/42/",
+			Duration: "1m24s",
+		},
+		{Job: "deploy", Status: jobNotRun, Build: "-", Duration: "-"},
+	}}}
+
+	path := filepath.Join(t.TempDir(), "card.json")
+	assert.NoError(t, writeCard(path, summary))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var card droneCard
+	assert.NoError(t, json.Unmarshal(data, &card))
+	assert.Equal(t, expected, card)
+
+	t.Run("stdout", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, writeCardTo(&buf, data))
+
+		encoded := strings.TrimSuffix(strings.TrimPrefix(buf.String(), "\u001B]1338;"), "\u001B]0m\n")
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(data), string(decoded))
+	})
+}
+
+// TestExecDroneOutputs tests writing the Drone output and card files after a run
+func TestExecDroneOutputs(t *testing.T) {
+	tj := newTestJenkins(t, map[string]string{"build": "SUCCESS"}, 1)
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "drone_output")
+	cardPath := filepath.Join(dir, "card.json")
+	assert.NoError(t, os.WriteFile(output, []byte("EXISTING=1\n"), 0o600))
+	t.Setenv("DRONE_OUTPUT", output)
+	t.Setenv("DRONE_CARD_PATH", cardPath)
+
+	plugin := Plugin{
+		BaseURL:      tj.URL,
+		Username:     testUserFoo,
+		Token:        testUserBar,
+		Job:          []string{"build"},
+		Wait:         true,
+		PollInterval: 10 * time.Millisecond,
+	}
+
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	env, err := godotenv.Read(output)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", env["EXISTING"])
+	assert.Equal(t, resultSuccess, env["JENKINS_RESULT"])
+	assert.Equal(t, tj.URL+"/job/build/1/", env["JENKINS_BUILD_URL"])
+	assert.Equal(t, "1", env["JENKINS_BUILD_NUMBER_BUILD"])
+	assert.Contains(t, env["JENKINS_RESULTS"], `"status":"succeeded"`)
+
+	data, err := os.ReadFile(cardPath)
+	assert.NoError(t, err)
+	var card droneCard
+	assert.NoError(t, json.Unmarshal(data, &card))
+	assert.Equal(t, cardSchema, card.Schema)
+	assert.Equal(t, "[#1]("+tj.URL+"/job/build/1/)", card.Data.Jobs[0].Build)
+}
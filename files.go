@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// parseFileParameters converts multi-line name=path pairs into file parameters,
+// checking that every file exists
+func parseFileParameters(input string) (map[string]string, error) {
+	files := make(map[string]string)
+	for name, paths := range parseParameters(input) {
+		path := paths[len(paths)-1]
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("file parameter %q: %w", name, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("file parameter %q: %s is a directory", name, path)
+		}
+
+		files[name] = path
+	}
+
+	return files, nil
+}
+
+// multipartBody returns a requestBody streaming the parameters and files as
+// multipart/form-data, so large files are never held in memory
+func multipartBody(params url.Values, files map[string]string) requestBody {
+	return func() (io.ReadCloser, string) {
+		reader, writer := io.Pipe()
+		form := multipart.NewWriter(writer)
+
+		// The pipe is closed by the HTTP client once the request is sent or has failed,
+		// which stops the writer early
+		go func() {
+			_ = writer.CloseWithError(writeMultipart(form, params, files))
+		}()
+
+		return reader, form.FormDataContentType()
+	}
+}
+
+// writeMultipart writes the parameters as form fields followed by the files
+func writeMultipart(form *multipart.Writer, params url.Values, files map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		for _, value := range params[name] {
+			if err := form.WriteField(name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if err := writeFormFile(form, name, files[name]); err != nil {
+			return err
+		}
+	}
+
+	return form.Close()
+}
+
+// writeFormFile copies a file into a form part named after the parameter
+func writeFormFile(form *multipart.Writer, name, path string) error {
+	file, err := os.Open(path) // #nosec G304 -- path is provided by the user
+	if err != nil {
+		return fmt.Errorf("failed to open file parameter %q: %w", name, err)
+	}
+	defer file.Close()
+
+	part, err := form.CreateFormFile(name, filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("failed to upload file parameter %q: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFileParameters(t *testing.T) {
+	path := writeTestFile(t, "config.json", `{}`)
+
+	files, err := parseFileParameters("CONFIG=" + path + "\n\n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"CONFIG": path}, files)
+
+	files, err = parseFileParameters("")
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	_, err = parseFileParameters("CONFIG=missing.json")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `file parameter "CONFIG"`)
+
+	_, err = parseFileParameters("CONFIG=" + t.TempDir())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is a directory")
+}
+
+// TestTriggerFileParameters tests uploading files as multipart/form-data,
+// sending the whole body again when the request is retried
+func TestTriggerFileParameters(t *testing.T) {
+	content := strings.Repeat("0123456789", 100000)
+	path := writeTestFile(t, "bundle.tar", content)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			_, _ = w.Write([]byte(`{"crumb":"abc","crumbRequestField":"Jenkins-Crumb"}`))
+			return
+		}
+
+		assert.Equal(t, "/job/test-job/buildWithParameters", r.URL.Path)
+		assert.Equal(t, "remote", r.URL.Query().Get(tokenParam))
+		assert.Equal(t, "abc", r.Header.Get("Jenkins-Crumb"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data"))
+
+		assert.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, []string{"main"}, r.MultipartForm.Value["BRANCH"])
+		assert.NotContains(t, r.MultipartForm.Value, tokenParam)
+
+		file, header, err := r.FormFile("BUNDLE")
+		assert.NoError(t, err)
+		assert.Equal(t, "bundle.tar", header.Filename)
+		data, _ := io.ReadAll(file)
+		assert.Equal(t, content, string(data))
+
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Location", "http://jenkins.example.com/queue/item/123/")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	jenkins := newTestRetryJenkins(t, server.URL)
+	jenkins.Auth = &Auth{Username: testUserFoo, Token: testUserBar}
+	jenkins.Token = "remote"
+
+	queueID, err := jenkins.trigger(
+		context.Background(),
+		testJobName,
+		url.Values{"BRANCH": {"main"}},
+		map[string]string{"BUNDLE": path},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 123, queueID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
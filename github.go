@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/com/gh"
+)
+
+// publishGitHub exposes the outcome of every job as GitHub Actions outputs and
+// writes a Markdown table to the step summary, when running in GitHub Actions
+func publishGitHub(summary *Summary) {
+	if os.Getenv("GITHUB_OUTPUT") != "" {
+		outputs, err := githubOutputs(summary)
+		if err == nil {
+			err = gh.SetOutput(outputs)
+		}
+		if err != nil {
+			slog.Warn("failed to set GitHub output", "error", err)
+		}
+	}
+
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		if err := appendFile(path, stepSummary(summary)); err != nil {
+			slog.Warn("failed to write GitHub step summary", "error", err)
+		}
+	}
+}
+
+// githubOutputs returns the per-job outputs, keyed by the job name, all job
+// records as a JSON array in the results output and the unsuffixed result, url
+// and tests_* outputs of the last configured job
+func githubOutputs(summary *Summary) (map[string]string, error) {
+	results, err := json.Marshal(summary.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]string{"results": string(results)}
+	for _, job := range summary.Jobs {
+		key := outputKey(job.Job)
+		outputs["status_"+key] = job.Status
+		if job.Result != "" {
+			outputs["result_"+key] = job.Result
+		}
+		if job.BuildNumber > 0 {
+			outputs["build_number_"+key] = strconv.Itoa(job.BuildNumber)
+		}
+		if job.URL != "" {
+			outputs["url_"+key] = job.URL
+		}
+	}
+
+	if len(summary.Jobs) == 0 {
+		return outputs, nil
+	}
+
+	last := summary.Jobs[len(summary.Jobs)-1]
+	if last.Result != "" {
+		outputs["result"] = last.Result
+	}
+	if last.URL != "" {
+		outputs["url"] = last.URL
+	}
+	if last.Tests != nil {
+		outputs["tests_passed"] = strconv.Itoa(last.Tests.Passed)
+		outputs["tests_failed"] = strconv.Itoa(last.Tests.Failed)
+		outputs["tests_skipped"] = strconv.Itoa(last.Tests.Skipped)
+		outputs["tests_total"] = strconv.Itoa(last.Tests.Total)
+	}
+
+	return outputs, nil
+}
+
+// outputKey converts a job name into an output name suffix, replacing every
+// character GitHub does not allow in output names with an underscore
+func outputKey(job string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, job)
+}
+
+// stepSummary formats the job outcomes as a Markdown table
+func stepSummary(summary *Summary) string {
+	var b strings.Builder
+	b.WriteString("### Jenkins jobs\n\n")
+	b.WriteString("| Job | Build | Result | Duration | Tests |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+
+	for _, job := range summary.Jobs {
+		tests := "-"
+		if job.Tests != nil {
+			tests = fmt.Sprintf(
+				"%d passed, %d failed, %d skipped",
+				job.Tests.Passed,
+				job.Tests.Failed,
+				job.Tests.Skipped,
+			)
+		}
+
+		_, _ = fmt.Fprintf(
+			&b,
+			"| %s | %s | %s | %s | %s |\n",
+			strings.ReplaceAll(job.Job, "|", `\|`),
+			job.buildLink(),
+			job.displayResult(),
+			job.duration(),
+			tests,
+		)
+	}
+
+	return b.String()
+}
+
+// appendFile appends content to a file, creating it if needed
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 -- path is set by the runner
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputKey(t *testing.T) {
+	assert.Equal(t, "test-job", outputKey(testJobName))
+	assert.Equal(t, "folder_sub_job_1", outputKey("folder/sub job.1"))
+}
+
+func TestGitHubOutputs(t *testing.T) {
+	summary := &Summary{Jobs: []JobSummary{
+		{
+			Job:         "folder/build",
+			Status:      nodeSucceeded,
+			QueueID:     3,
+			BuildNumber: 7,
+			URL:         testExampleURL + "/job/folder/job/build/7/",
+			Result:      resultSuccess,
+		},
+		{Job: "deploy", Status: jobNotRun},
+	}}
+
+	outputs, err := githubOutputs(summary)
+	assert.NoError(t, err)
+	assert.Equal(t, "succeeded", outputs["status_folder_build"])
+	assert.Equal(t, resultSuccess, outputs["result_folder_build"])
+	assert.Equal(t, "7", outputs["build_number_folder_build"])
+	assert.Equal(t, testExampleURL+"/job/folder/job/build/7/", outputs["url_folder_build"])
+	assert.Equal(t, "not_run", outputs["status_deploy"])
+	assert.NotContains(t, outputs, "result_deploy")
+	assert.NotContains(t, outputs, "build_number_deploy")
+	assert.NotContains(t, outputs, "result")
+	assert.NotContains(t, outputs, "url")
+	assert.NotContains(t, outputs, "tests_total")
+
+	var results []JobSummary
+	assert.NoError(t, json.Unmarshal([]byte(outputs["results"]), &results))
+	assert.Equal(t, summary.Jobs, results)
+
+	t.Run("last job", func(t *testing.T) {
+		summary := &Summary{Jobs: []JobSummary{
+			{Job: "build", Status: nodeSucceeded, BuildNumber: 7, URL: testExampleURL + "/job/build/7/", Result: resultSuccess},
+			{
+				Job:         "test",
+				Status:      nodeFailed,
+				BuildNumber: 3,
+				URL:         testExampleURL + "/job/test/3/",
+				Result:      resultUnstable,
+				Tests:       &TestCounts{Passed: 8, Failed: 1, Skipped: 1, Total: 10},
+			},
+		}}
+
+		outputs, err := githubOutputs(summary)
+		assert.NoError(t, err)
+		assert.Equal(t, resultUnstable, outputs["result"])
+		assert.Equal(t, testExampleURL+"/job/test/3/", outputs["url"])
+		assert.Equal(t, "8", outputs["tests_passed"])
+		assert.Equal(t, "1", outputs["tests_failed"])
+		assert.Equal(t, "1", outputs["tests_skipped"])
+		assert.Equal(t, "10", outputs["tests_total"])
+	})
+}
+
+func TestStepSummary(t *testing.T) {
+	summary := &Summary{Jobs: []JobSummary{
+		{
+			Job:         "build",
+			Status:      nodeSucceeded,
+			BuildNumber: 42,
+			URL:         testExampleURL + "/job/build/42/",
+			Result:      resultSuccess,
+			DurationMs:  83512,
+			Tests:       &TestCounts{Passed: 120, Skipped: 2, Total: 122},
+		},
+		{Job: "a|b", Status: nodeFailed, Error: "failed to trigger job"},
+	}}
+
+	assert.Equal(t,
+		"### Jenkins jobs\n\n"+
+			"| Job | Build | Result | Duration | Tests |\n"+
+			"| --- | --- | --- | --- | --- |\n"+
+			"| build | [#42]("+testExampleURL+"/job/build/42/) | SUCCESS | 1m24s | 120 passed, 0 failed, 2 skipped |\n"+
+			"| a\\|b | - | failed | - | - |\n",
+		stepSummary(summary),
+	)
+}
+
+// TestExecGitHubOutputs tests exposing the outcome of every job when running in GitHub Actions
+func TestExecGitHubOutputs(t *testing.T) {
+	tj := newTestJenkins(t, map[string]string{
+		"build":  "SUCCESS",
+		"deploy": "UNSTABLE",
+	}, 1)
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "github_output")
+	stepSummary := filepath.Join(dir, "step_summary")
+	assert.NoError(t, os.WriteFile(output, nil, 0o600))
+	t.Setenv("GITHUB_OUTPUT", output)
+	t.Setenv("GITHUB_STEP_SUMMARY", stepSummary)
+
+	plugin := Plugin{
+		BaseURL:       tj.URL,
+		Username:      testUserFoo,
+		Token:         testUserBar,
+		Job:           []string{"build", "deploy"},
+		Wait:          true,
+		AcceptResults: []string{resultSuccess, resultUnstable},
+		PollInterval:  10 * time.Millisecond,
+	}
+
+	assert.NoError(t, plugin.Exec(context.Background()))
+
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "result_build=SUCCESS\n")
+	assert.Contains(t, string(data), "build_number_build=1\n")
+	assert.Contains(t, string(data), "result_deploy=UNSTABLE\n")
+	assert.Contains(t, string(data), "build_number_deploy=2\n")
+	assert.Equal(t, 1, strings.Count("\n"+string(data), "\nresult="))
+	assert.Contains(t, "\n"+string(data), "\nresult=UNSTABLE\n")
+	assert.Contains(t, string(data), "url="+tj.URL+"/job/deploy/2/\n")
+	assert.Contains(t, string(data), `results=[{"job":"build","status":"succeeded"`)
+
+	data, err = os.ReadFile(stepSummary)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "| build | [#1]("+tj.URL+"/job/build/1/) | SUCCESS | - | - |\n")
+	assert.Contains(t, string(data), "| deploy | [#2]("+tj.URL+"/job/deploy/2/) | UNSTABLE | - | - |\n")
+}
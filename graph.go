@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Status of a job in the dependency graph
+const (
+	nodeSucceeded = "succeeded"
+	nodeFailed    = "failed"
+	nodeSkipped   = "skipped"
+)
+
+// nodeResult holds the outcome of a single job in the dependency graph
+type nodeResult struct {
+	status string
+	build  *BuildInfo
+	err    error
+}
+
+// parseJobLists converts a multi-line string of job=item1,item2 entries
+// into a map from job name to its list of items, e.g. the jobs it depends on.
+func parseJobLists(input string) map[string][]string {
+	lists := map[string][]string{}
+
+	for job, values := range parseParameters(input) {
+		for _, value := range values {
+			lists[job] = append(lists[job], trimWhitespaceFromSlice(strings.Split(value, ","))...)
+		}
+	}
+
+	return lists
+}
+
+// sortGraph validates the dependency graph and returns the jobs in topological order.
+// It returns an error if a dependency refers to an unknown job or the graph has a cycle.
+func sortGraph(jobs []string, deps map[string][]string) ([]string, error) {
+	known := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		known[job] = true
+	}
+
+	for job := range deps {
+		if !known[job] {
+			return nil, fmt.Errorf("dependencies declared for unknown job %q", job)
+		}
+	}
+
+	// Dependents are collected in job order, as map iteration order is random
+	pending := make(map[string]int, len(jobs))
+	dependents := map[string][]string{}
+	for _, job := range jobs {
+		for _, dep := range deps[job] {
+			if !known[dep] {
+				return nil, fmt.Errorf("job %q depends on unknown job %q", job, dep)
+			}
+			pending[job]++
+			dependents[dep] = append(dependents[dep], job)
+		}
+	}
+
+	// Kahn's algorithm, keeping the configured job order for independent jobs
+	order := make([]string, 0, len(jobs))
+	var ready []string
+	for _, job := range jobs {
+		if pending[job] == 0 {
+			ready = append(ready, job)
+		}
+	}
+
+	for len(ready) > 0 {
+		job := ready[0]
+		ready = ready[1:]
+		order = append(order, job)
+
+		for _, next := range dependents[job] {
+			pending[next]--
+			if pending[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+
+	if len(order) != len(jobs) {
+		return nil, errors.New("job dependencies contain a cycle")
+	}
+
+	return order, nil
+}
+
+// runGraph runs the jobs in dependency order. Jobs whose dependencies have all
+// succeeded start concurrently, limited by maxParallel (unlimited when zero).
+// Jobs downstream of a failed job are skipped.
+func (r *runner) runGraph(ctx context.Context, jobs []string, deps map[string][]string) error {
+	order, err := sortGraph(jobs, deps)
+	if err != nil {
+		return fmt.Errorf("invalid job dependencies: %w", err)
+	}
+
+	// Dependencies can only be honoured by waiting for each job to finish
+	r.forceWait = true
+
+	limit := r.maxParallel
+	if limit <= 0 || limit > len(jobs) {
+		limit = len(jobs)
+	}
+
+	type done struct {
+		job    string
+		result nodeResult
+	}
+
+	results := make(map[string]nodeResult, len(jobs))
+	finished := make(chan done)
+	running := 0
+
+	// start launches every job that is ready, up to the concurrency limit.
+	// Jobs with a failed or skipped dependency are marked as skipped.
+	start := func() {
+		for changed := true; changed; {
+			changed = false
+			for _, job := range order {
+				if _, ok := results[job]; ok || running >= limit {
+					continue
+				}
+
+				ready, skip := true, false
+				for _, dep := range deps[job] {
+					res, ok := results[dep]
+					switch {
+					case !ok || res.status == "":
+						ready = false
+					case res.status != nodeSucceeded:
+						skip = true
+					}
+				}
+
+				if skip {
+					slog.Warn("skipping job, an upstream job did not succeed", "job", job)
+					results[job] = nodeResult{status: nodeSkipped}
+					r.summary.skip(job, nodeSkipped)
+					changed = true
+					continue
+				}
+				if !ready {
+					continue
+				}
+
+				results[job] = nodeResult{} // Running
+				running++
+				go func() {
+					build, err := r.runJob(ctx, job)
+					res := nodeResult{status: nodeSucceeded, build: build, err: err}
+					if err != nil {
+						res.status = nodeFailed
+					}
+					finished <- done{job: job, result: res}
+				}()
+			}
+		}
+	}
+
+	start()
+	for running > 0 {
+		d := <-finished
+		running--
+		results[d.job] = d.result
+		start()
+	}
+
+	logGraphResults(order, results)
+
+	var errs []error
+	failed, skipped := 0, 0
+	for _, job := range order {
+		switch results[job].status {
+		case nodeFailed:
+			failed++
+			errs = append(errs, results[job].err)
+		case nodeSkipped:
+			skipped++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf(
+			"%d of %d jobs failed, %d skipped: %w",
+			failed,
+			len(jobs),
+			skipped,
+			errors.Join(errs...),
+		)
+	}
+
+	return nil
+}
+
+// logGraphResults logs a table with the outcome of every job in topological order
+func logGraphResults(order []string, results map[string]nodeResult) {
+	table := logTable{columns: []string{"job", "status", "build_number", "result", "duration"}}
+	for _, job := range order {
+		res := results[job]
+		row := []any{job, res.status, nil, nil, nil}
+		if res.build != nil {
+			row[2] = res.build.Number
+			row[3] = res.build.Result
+			row[4] = time.Duration(res.build.Duration) * time.Millisecond
+		}
+		table.rows = append(table.rows, row)
+	}
+	slog.Info("job graph results", "jobs", table)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJobLists(t *testing.T) {
+	deps := parseJobLists("test=build\ndeploy=build, test\n\nsmoke=deploy")
+
+	assert.Equal(t, map[string][]string{
+		"test":   {"build"},
+		"deploy": {"build", "test"},
+		"smoke":  {"deploy"},
+	}, deps)
+}
+
+func TestSortGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		jobs     []string
+		deps     map[string][]string
+		expected []string
+		errorMsg string
+	}{
+		{
+			name:     "no dependencies keeps job order",
+			jobs:     []string{"a", "b", "c"},
+			deps:     map[string][]string{},
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name: "chain",
+			jobs: []string{"smoke", "deploy", "test", "build"},
+			deps: map[string][]string{
+				"test":   {"build"},
+				"deploy": {"test"},
+				"smoke":  {"deploy"},
+			},
+			expected: []string{"build", "test", "deploy", "smoke"},
+		},
+		{
+			name: "diamond",
+			jobs: []string{"build", "unit", "lint", "deploy"},
+			deps: map[string][]string{
+				"unit":   {"build"},
+				"lint":   {"build"},
+				"deploy": {"unit", "lint"},
+			},
+			expected: []string{"build", "unit", "lint", "deploy"},
+		},
+		{
+			name:     "unknown dependency",
+			jobs:     []string{"build", "deploy"},
+			deps:     map[string][]string{"deploy": {"test"}},
+			errorMsg: `job "deploy" depends on unknown job "test"`,
+		},
+		{
+			name:     "unknown job",
+			jobs:     []string{"build"},
+			deps:     map[string][]string{"deploy": {"build"}},
+			errorMsg: `dependencies declared for unknown job "deploy"`,
+		},
+		{
+			name: "cycle",
+			jobs: []string{"a", "b", "c"},
+			deps: map[string][]string{
+				"a": {"c"},
+				"b": {"a"},
+				"c": {"b"},
+			},
+			errorMsg: "cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := sortGraph(tt.jobs, tt.deps)
+			if tt.errorMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, order)
+		})
+	}
+}
+
+// TestExecGraph tests running jobs in dependency order
+func TestExecGraph(t *testing.T) {
+	t.Run("runs jobs after their dependencies", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{
+			"build":  "SUCCESS",
+			"unit":   "SUCCESS",
+			"lint":   "SUCCESS",
+			"deploy": "SUCCESS",
+		}, 2)
+
+		plugin := Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"deploy", "unit", "lint", "build"},
+			DependsOn:    "unit=build\nlint=build\ndeploy=unit,lint",
+			PollInterval: 10 * time.Millisecond,
+		}
+
+		err := plugin.Exec(context.Background())
+
+		assert.NoError(t, err)
+		triggered := tj.triggeredJobs()
+		assert.Len(t, triggered, 4)
+		assert.Equal(t, "build", triggered[0])
+		assert.ElementsMatch(t, []string{"unit", "lint"}, triggered[1:3])
+		assert.Equal(t, "deploy", triggered[3])
+	})
+
+	t.Run("skips jobs downstream of a failure", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{
+			"build":  "SUCCESS",
+			"test":   "FAILURE",
+			"docs":   "SUCCESS",
+			"deploy": "SUCCESS",
+			"smoke":  "SUCCESS",
+		}, 1)
+
+		plugin := Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"build", "test", "docs", "deploy", "smoke"},
+			DependsOn:    "test=build\ndocs=build\ndeploy=test\nsmoke=deploy",
+			PollInterval: 10 * time.Millisecond,
+		}
+
+		err := plugin.Exec(context.Background())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "1 of 5 jobs failed, 2 skipped")
+		assert.Contains(t, err.Error(), "failed with status: FAILURE")
+		assert.ElementsMatch(t, []string{"build", "test", "docs"}, tj.triggeredJobs())
+	})
+
+	t.Run("invalid graph", func(t *testing.T) {
+		plugin := Plugin{
+			BaseURL:   testExampleURL,
+			Username:  testUserFoo,
+			Token:     testUserBar,
+			Job:       []string{"a", "b"},
+			DependsOn: "a=b\nb=a",
+		}
+
+		err := plugin.Exec(context.Background())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid job dependencies")
+	})
+}
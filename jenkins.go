@@ -5,22 +5,25 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
-
-	"github.com/appleboy/com/gh"
-	"github.com/yassinebenaid/godump"
 )
 
 const tokenParam = "token"
 
+// errTimeout is wrapped by errors returned when waiting exceeds the configured timeout
+var errTimeout = errors.New("timeout")
+
 type (
 	// Auth contain username and token
 	Auth struct {
@@ -30,12 +33,24 @@ type (
 
 	// Jenkins contain Auth and BaseURL
 	Jenkins struct {
-		Auth    *Auth
-		BaseURL string
-		Token   string // Remote trigger token
-		Client  *http.Client
-		Debug   bool           // Enable debug mode to show detailed information
-		crumb   *CrumbResponse // Cached CSRF crumb
+		Auth          *Auth
+		Authenticator Authenticator // Credentials other than basic auth, e.g. a bearer token
+		Headers       http.Header   // Extra headers sent with every request
+		BaseURL       string
+		Token         string // Remote trigger token
+		Client        *http.Client
+		Debug         bool           // Enable debug mode to show detailed information
+		FollowLog     bool           // Stream the build console log while waiting for completion
+		StageProgress bool           // Log Pipeline stage transitions while waiting for completion
+		LogPrefix     bool           // Prefix streamed console lines with the job name
+		AbortOnCancel bool           // Abort the queued or running build when waiting is cancelled
+		QueryParams   bool           // Send build parameters and the token in the query string
+		Retry         RetryPolicy    // Retry policy for transient request failures
+		crumb         *CrumbResponse // Cached CSRF crumb
+		crumbMu       sync.Mutex     // Guards crumb for concurrent jobs
+		console       io.Writer      // Destination of the streamed console log
+		consoleMu     sync.Mutex     // Serializes console writes from concurrent jobs
+		abortGrace    time.Duration  // Time to wait for a build to stop before escalating
 	}
 
 	// CrumbResponse represents Jenkins crumb issuer response for CSRF protection
@@ -54,66 +69,122 @@ type (
 			Number int    `json:"number"`
 			URL    string `json:"url"`
 		} `json:"executable"`
-		Why string `json:"why"`
+		Why  string `json:"why"`
+		Task *struct {
+			Name string `json:"name"`
+			URL  string `json:"url"`
+		} `json:"task"`
 	}
 
 	// BuildInfo represents Jenkins build information
 	BuildInfo struct {
-		Building  bool   `json:"building"`
-		Duration  int64  `json:"duration"`
-		Result    string `json:"result"` // SUCCESS, FAILURE, ABORTED, UNSTABLE, null if building
-		Number    int    `json:"number"`
-		URL       string `json:"url"`
-		Timestamp int64  `json:"timestamp"`
+		Building  bool       `json:"building"`
+		Duration  int64      `json:"duration"`
+		Result    string     `json:"result"` // SUCCESS, FAILURE, ABORTED, UNSTABLE, null if building
+		Number    int        `json:"number"`
+		URL       string     `json:"url"`
+		Timestamp int64      `json:"timestamp"`
+		Artifacts []Artifact `json:"artifacts"`
+	}
+
+	// HTTPError represents an unexpected HTTP response status from Jenkins
+	HTTPError struct {
+		StatusCode int
+		Body       string
 	}
+
+	// requestBody creates a request body and its content type.
+	// It is called once per attempt so that retried requests send the whole body again.
+	requestBody func() (io.ReadCloser, string)
 )
 
-// loadCACert loads a CA certificate from various sources:
+// Error implements the error interface
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected response code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// cloneValues returns a deep copy of params that is safe to modify
+func cloneValues(params url.Values) url.Values {
+	clone := make(url.Values, len(params))
+	for key, values := range params {
+		clone[key] = append([]string(nil), values...)
+	}
+
+	return clone
+}
+
+// sleepContext pauses for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isNotFound reports whether err is a Jenkins 404 response
+func isNotFound(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+// loadCACert loads a CA certificate from PEM content, a file path or an HTTP/HTTPS URL
+func loadCACert(ctx context.Context, client *http.Client, caCert string) ([]byte, error) {
+	return loadPEM(ctx, client, caCert, "CA certificate")
+}
+
+// loadPEM loads PEM data, described by name in errors, from various sources:
 // - PEM content (if it starts with "-----BEGIN")
 // - File path (if the file exists)
-// - HTTP/HTTPS URL (if it starts with "http://" or "https://")
-func loadCACert(ctx context.Context, caCert string) ([]byte, error) {
-	if caCert == "" {
+// - HTTP/HTTPS URL (if it starts with "http://" or "https://"), fetched with client
+func loadPEM(ctx context.Context, client *http.Client, source, name string) ([]byte, error) {
+	if source == "" {
 		return nil, nil
 	}
 
 	// Check if it's PEM content (starts with BEGIN marker)
-	if strings.HasPrefix(strings.TrimSpace(caCert), "-----BEGIN") {
-		return []byte(caCert), nil
+	if strings.HasPrefix(strings.TrimSpace(source), "-----BEGIN") {
+		return []byte(source), nil
 	}
 
 	// Check if it's an HTTP/HTTPS URL
-	if strings.HasPrefix(caCert, "http://") || strings.HasPrefix(caCert, "https://") {
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, caCert, nil)
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request for CA certificate URL: %w", err)
+			return nil, fmt.Errorf("failed to create request for %s URL: %w", name, err)
 		}
-		resp, err := http.DefaultClient.Do(req) // #nosec G107 -- URL is user-provided configuration
+		resp, err := client.Do(req) // #nosec G107 -- URL is user-provided configuration
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch CA certificate from URL: %w", err)
+			return nil, fmt.Errorf("failed to fetch %s from URL: %w", name, err)
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("failed to fetch CA certificate: HTTP %d", resp.StatusCode)
+			return nil, fmt.Errorf("failed to fetch %s: HTTP %d", name, resp.StatusCode)
 		}
 
 		data, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read CA certificate from URL: %w", err)
+			return nil, fmt.Errorf("failed to read %s from URL: %w", name, err)
 		}
 		return data, nil
 	}
 
 	// Otherwise, treat it as a file path
-	data, err := os.ReadFile(caCert)
+	data, err := os.ReadFile(source)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+		return nil, fmt.Errorf("failed to read %s file: %w", name, err)
 	}
 	return data, nil
 }
 
-// NewJenkins is initial Jenkins object
+// NewJenkins is initial Jenkins object. The client certificate and key are presented
+// to servers enforcing mutual TLS, an encrypted key is decrypted with keyPassphrase,
+// and proxyURL overrides the proxy environment variables.
 func NewJenkins(
 	ctx context.Context,
 	auth *Auth,
@@ -121,12 +192,29 @@ func NewJenkins(
 	token string,
 	insecure bool,
 	caCert string,
+	clientCert string,
+	clientKey string,
+	keyPassphrase string,
+	proxyURL string,
 	debug bool,
 ) (*Jenkins, error) {
 	baseURL = strings.TrimRight(baseURL, "/")
 
+	proxy, err := proxyFunc(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != "" {
+		slog.Debug("using proxy", "proxy", redactURL(proxyURL))
+	}
+
+	// Certificates given as URLs are fetched through the same proxy as Jenkins requests
+	fetchTransport := http.DefaultTransport.(*http.Transport).Clone()
+	fetchTransport.Proxy = proxy
+	fetchClient := &http.Client{Transport: fetchTransport}
+
 	// Load CA certificate if provided
-	caCertData, err := loadCACert(ctx, caCert)
+	caCertData, err := loadCACert(ctx, fetchClient, caCert)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load CA certificate: %w", err)
 	}
@@ -154,6 +242,19 @@ func NewJenkins(
 		}
 	}
 
+	// Present a client certificate to servers enforcing mutual TLS
+	if clientCert != "" || clientKey != "" {
+		cert, err := loadClientCert(ctx, fetchClient, clientCert, clientKey, keyPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
 	// Create CookieJar for session management (required for CSRF crumb)
 	jar, err := cookiejar.New(nil)
 	if err != nil {
@@ -162,6 +263,7 @@ func NewJenkins(
 
 	// Create HTTP Transport with optional TLS configuration
 	transport := &http.Transport{
+		Proxy:           proxy,
 		TLSClientConfig: tlsConfig,
 	}
 
@@ -172,11 +274,14 @@ func NewJenkins(
 	}
 
 	return &Jenkins{
-		Auth:    auth,
-		BaseURL: baseURL,
-		Token:   token,
-		Client:  client,
-		Debug:   debug,
+		Auth:       auth,
+		BaseURL:    baseURL,
+		Token:      token,
+		Client:     client,
+		Debug:      debug,
+		Retry:      defaultRetryPolicy(),
+		console:    os.Stdout,
+		abortGrace: defaultAbortGrace,
 	}, nil
 }
 
@@ -192,11 +297,28 @@ func (jenkins *Jenkins) buildURL(path string, params url.Values) (requestURL str
 	return
 }
 
+// isCrumbRejected reports whether Jenkins refused a request because of a missing or expired crumb
+func isCrumbRejected(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) &&
+		httpErr.StatusCode == http.StatusForbidden &&
+		strings.Contains(httpErr.Body, "No valid crumb")
+}
+
+// isCrumbUnavailable reports whether a crumb issuer error means CSRF protection is disabled,
+// as opposed to a genuine error such as an authentication failure or a network error
+func isCrumbUnavailable(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) &&
+		(httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode < http.StatusBadRequest)
+}
+
 // getCrumb fetches CSRF crumb from Jenkins
 // Returns nil if CSRF protection is disabled on Jenkins
-//
-//nolint:unparam // Error return kept for future extensibility and API consistency
 func (jenkins *Jenkins) getCrumb(ctx context.Context) (*CrumbResponse, error) {
+	jenkins.crumbMu.Lock()
+	defer jenkins.crumbMu.Unlock()
+
 	// Return cached crumb if available
 	if jenkins.crumb != nil {
 		return jenkins.crumb, nil
@@ -204,31 +326,63 @@ func (jenkins *Jenkins) getCrumb(ctx context.Context) (*CrumbResponse, error) {
 
 	path := "/crumbIssuer/api/json"
 	var crumb CrumbResponse
-	err := jenkins.get(ctx, path, nil, &crumb)
-	if err != nil {
-		// CSRF protection might be disabled, log and continue
-		if jenkins.Debug {
-			log.Printf("crumb not available (CSRF may be disabled): %v", err)
-		}
+	data, _, err := jenkins.getRaw(ctx, path, nil)
+	if err != nil && !isCrumbUnavailable(err) {
+		return nil, err
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &crumb)
+	}
+	if err != nil || crumb.Crumb == "" {
+		// CSRF protection is disabled, log and continue
+		slog.Debug("crumb not available, CSRF protection may be disabled", "error", err)
 		return nil, nil
 	}
 
 	// Cache the crumb for subsequent requests
 	jenkins.crumb = &crumb
-	if jenkins.Debug {
-		log.Printf("obtained crumb: %s=%s", crumb.CrumbRequestField, crumb.Crumb)
-	}
+	slog.Debug("obtained crumb", "field", crumb.CrumbRequestField, "crumb", crumb.Crumb)
 
 	return jenkins.crumb, nil
 }
 
+// invalidateCrumb drops the cached crumb if it is still the given rejected one,
+// so concurrent jobs that were rejected with the same crumb fetch it only once
+func (jenkins *Jenkins) invalidateCrumb(rejected *CrumbResponse) {
+	jenkins.crumbMu.Lock()
+	defer jenkins.crumbMu.Unlock()
+
+	if jenkins.crumb == rejected {
+		jenkins.crumb = nil
+	}
+}
+
+// authenticated reports whether requests are sent with credentials
+func (jenkins *Jenkins) authenticated() bool {
+	return jenkins.Authenticator != nil ||
+		(jenkins.Auth != nil && jenkins.Auth.Username != "" && jenkins.Auth.Token != "")
+}
+
 func (jenkins *Jenkins) sendRequest(
 	req *http.Request,
 	crumb *CrumbResponse,
 ) (*http.Response, error) {
+	// Extra headers are set first so that they cannot replace the credentials
+	for name, values := range jenkins.Headers {
+		req.Header[name] = slices.Clone(values)
+	}
+
 	if jenkins.Auth != nil && jenkins.Auth.Username != "" && jenkins.Auth.Token != "" {
 		req.SetBasicAuth(jenkins.Auth.Username, jenkins.Auth.Token)
 	}
+	if jenkins.Authenticator != nil {
+		if err := jenkins.Authenticator.Authenticate(req); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, err
+		}
+	}
 
 	// Add CSRF crumb header if available
 	if crumb != nil && crumb.CrumbRequestField != "" {
@@ -244,80 +398,142 @@ func (jenkins *Jenkins) get(
 	params url.Values,
 	body interface{},
 ) error {
-	requestURL := jenkins.buildURL(path, params)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	data, _, err := jenkins.getRaw(ctx, path, params)
 	if err != nil {
 		return err
 	}
 
-	resp, err := jenkins.sendRequest(req, nil) // GET requests don't need crumb
+	if body == nil {
+		return nil
+	}
+
+	return json.Unmarshal(data, body)
+}
+
+// getRaw performs a GET request and returns the raw response body and headers
+func (jenkins *Jenkins) getRaw(
+	ctx context.Context,
+	path string,
+	params url.Values,
+) ([]byte, http.Header, error) {
+	requestURL := jenkins.buildURL(path, params)
+
+	resp, err := jenkins.doWithRetry(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	}, nil) // GET requests don't need crumb
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	defer resp.Body.Close()
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected response code: %d, body: %s", resp.StatusCode, string(data))
-	}
-
-	if body == nil {
-		return nil
+		return nil, nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(data)}
 	}
 
-	return json.Unmarshal(data, body)
+	return data, resp.Header, nil
 }
 
-// postAndGetLocation performs a POST request and extracts the queue ID from Location header
-func (jenkins *Jenkins) postAndGetLocation(
+// post performs a POST request with the CSRF crumb and returns the response headers and body.
+// The request has no body unless body is given.
+// If Jenkins rejects the crumb, e.g. because the session expired, a new crumb is fetched
+// and the request is sent once more.
+func (jenkins *Jenkins) post(
 	ctx context.Context,
 	path string,
 	params url.Values,
-) (int, error) {
-	// Fetch CSRF crumb before POST request (only if authenticated)
-	var crumb *CrumbResponse
-	if jenkins.Auth != nil && jenkins.Auth.Username != "" && jenkins.Auth.Token != "" {
-		var err error
-		crumb, err = jenkins.getCrumb(ctx)
-		if err != nil {
-			return 0, fmt.Errorf("failed to get crumb: %w", err)
+	body requestBody,
+) (http.Header, []byte, error) {
+	requestURL := jenkins.buildURL(path, params)
+
+	for attempt := 1; ; attempt++ {
+		// Fetch CSRF crumb before POST request (only if authenticated)
+		var crumb *CrumbResponse
+		if jenkins.authenticated() {
+			var err error
+			crumb, err = jenkins.getCrumb(ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get crumb: %w", err)
+			}
 		}
-	}
 
-	requestURL := jenkins.buildURL(path, params)
+		header, data, err := jenkins.postWithCrumb(ctx, requestURL, body, crumb)
+		if attempt == 1 && crumb != nil && isCrumbRejected(err) {
+			slog.Warn("jenkins rejected the CSRF crumb, fetching a new one")
+			jenkins.invalidateCrumb(crumb)
+			continue
+		}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, nil)
-	if err != nil {
-		return 0, err
+		return header, data, err
 	}
+}
 
-	resp, err := jenkins.sendRequest(req, crumb)
+// postWithCrumb sends a single POST request with the given crumb
+func (jenkins *Jenkins) postWithCrumb(
+	ctx context.Context,
+	requestURL string,
+	body requestBody,
+	crumb *CrumbResponse,
+) (http.Header, []byte, error) {
+	resp, err := jenkins.doWithRetry(ctx, func() (*http.Request, error) {
+		if body == nil {
+			return http.NewRequestWithContext(ctx, "POST", requestURL, nil)
+		}
+
+		// Every attempt needs a fresh body, as a sent one cannot be read again
+		reader, contentType := body()
+		req, err := http.NewRequestWithContext(ctx, "POST", requestURL, reader)
+		if err != nil {
+			_ = reader.Close()
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		return req, nil
+	}, crumb)
 	if err != nil {
-		return 0, err
+		return nil, nil, err
 	}
 
 	defer resp.Body.Close()
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf(
-			"unexpected response code: %d, body: %s",
-			resp.StatusCode,
-			string(data),
-		)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	return resp.Header, data, nil
+}
+
+// formBody returns a requestBody sending params as application/x-www-form-urlencoded
+func formBody(params url.Values) requestBody {
+	encoded := params.Encode()
+	return func() (io.ReadCloser, string) {
+		return io.NopCloser(strings.NewReader(encoded)), "application/x-www-form-urlencoded"
+	}
+}
+
+// postAndGetLocation performs a POST request and extracts the queue ID from Location header
+func (jenkins *Jenkins) postAndGetLocation(
+	ctx context.Context,
+	path string,
+	params url.Values,
+	body requestBody,
+) (int, error) {
+	header, _, err := jenkins.post(ctx, path, params, body)
+	if err != nil {
+		return 0, err
 	}
 
 	// Extract queue ID from Location header
 	// Location format: http://jenkins.example.com/queue/item/123/
-	location := resp.Header.Get("Location")
+	location := header.Get("Location")
 	if location == "" {
 		return 0, fmt.Errorf("no Location header in response")
 	}
@@ -389,112 +605,266 @@ func (jenkins *Jenkins) getBuildInfo(
 	return &buildInfo, nil
 }
 
+// findBuildByQueueID returns the number of the recent build started from the given
+// queue item, or zero if there is none
+func (jenkins *Jenkins) findBuildByQueueID(ctx context.Context, job string, queueID int) (int, error) {
+	path := jenkins.parseJobPath(job) + "/api/json"
+	params := url.Values{"tree": []string{"builds[number,queueId]{0,100}"}}
+
+	var jobInfo struct {
+		Builds []struct {
+			Number  int `json:"number"`
+			QueueID int `json:"queueId"`
+		} `json:"builds"`
+	}
+	if err := jenkins.get(ctx, path, params, &jobInfo); err != nil {
+		return 0, fmt.Errorf("failed to get builds of %s: %w", job, err)
+	}
+
+	for _, build := range jobInfo.Builds {
+		if build.QueueID == queueID {
+			return build.Number, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// getLastBuildNumber returns the number of the most recent build of a job
+func (jenkins *Jenkins) getLastBuildNumber(ctx context.Context, job string) (int, error) {
+	path := jenkins.parseJobPath(job) + "/api/json"
+	params := url.Values{"tree": []string{"lastBuild[number]"}}
+
+	var jobInfo struct {
+		LastBuild *struct {
+			Number int `json:"number"`
+		} `json:"lastBuild"`
+	}
+	if err := jenkins.get(ctx, path, params, &jobInfo); err != nil {
+		return 0, fmt.Errorf("failed to get job info for %s: %w", job, err)
+	}
+	if jobInfo.LastBuild == nil {
+		return 0, fmt.Errorf("job %s has no builds", job)
+	}
+
+	return jobInfo.LastBuild.Number, nil
+}
+
 // waitForCompletion waits for a Jenkins build to complete
-// It first polls the queue to get the build number, then polls the build status until completion
+// It first polls the queue to get the build number, then polls the build status until completion.
+// Once the build started, a timeout or cancellation still returns the last known build info.
 func (jenkins *Jenkins) waitForCompletion(
 	ctx context.Context,
 	job string,
 	queueID int,
 	pollInterval, timeout time.Duration,
-) (*BuildInfo, error) {
+) (_ *BuildInfo, err error) {
 	deadline := time.Now().Add(timeout)
 
 	// Phase 1: Wait for queue item to be assigned a build number
-	log.Printf("waiting for job %s (queue #%d) to start...", job, queueID)
+	slog.Info("waiting for job to start", "job", job, "queue_id", queueID)
 	var buildNumber int
 
+	// Abort the queue item or build if waiting was cancelled or timed out
+	if jenkins.AbortOnCancel {
+		defer func() {
+			if err == nil || (ctx.Err() == nil && !errors.Is(err, errTimeout)) {
+				return
+			}
+
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+			defer cancel()
+			if abortErr := jenkins.abort(abortCtx, job, queueID, buildNumber); abortErr != nil {
+				slog.Warn(
+					"failed to abort job",
+					"job", job,
+					"queue_id", queueID,
+					"build_number", buildNumber,
+					"error", abortErr,
+				)
+			}
+		}()
+	}
+
 	for {
 		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timeout waiting for job %s to start", job)
+			return nil, fmt.Errorf("%w waiting for job %s to start", errTimeout, job)
 		}
 
 		queueItem, err := jenkins.getQueueItem(ctx, queueID)
 		if err != nil {
+			// Jenkins forgets queue items a few minutes after the build started,
+			// e.g. when resuming a wait, so look for the build in the job history
+			if isNotFound(err) {
+				if number, findErr := jenkins.findBuildByQueueID(ctx, job, queueID); findErr != nil {
+					slog.Warn("failed to find build", "job", job, "queue_id", queueID, "error", findErr)
+				} else if number > 0 {
+					buildNumber = number
+					slog.Info("job started", "job", job, "queue_id", queueID, "build_number", buildNumber)
+					break
+				}
+			}
+
 			// Queue item might be deleted after build starts, try to continue
-			log.Printf("warning: failed to get queue item: %v", err)
-			time.Sleep(pollInterval)
+			slog.Warn("failed to get queue item", "job", job, "queue_id", queueID, "error", err)
+			if err := sleepContext(ctx, pollInterval); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
 		// Check if build has started
 		if queueItem.Executable != nil && queueItem.Executable.Number > 0 {
 			buildNumber = queueItem.Executable.Number
-			log.Printf("job %s started as build #%d", job, buildNumber)
+			slog.Info("job started", "job", job, "queue_id", queueID, "build_number", buildNumber)
 			break
 		}
 
 		// Log why the job is waiting if available
 		if queueItem.Why != "" {
-			log.Printf("job %s is queued: %s", job, queueItem.Why)
+			slog.Info("job is queued", "job", job, "queue_id", queueID, "reason", queueItem.Why)
 		}
 
-		time.Sleep(pollInterval)
+		if err := sleepContext(ctx, pollInterval); err != nil {
+			return nil, err
+		}
 	}
 
 	// Phase 2: Wait for build to complete
-	log.Printf("waiting for job %s (build #%d) to complete...", job, buildNumber)
+	return jenkins.pollBuild(ctx, job, buildNumber, pollInterval, deadline)
+}
+
+// waitForBuild waits for an already started Jenkins build to complete
+func (jenkins *Jenkins) waitForBuild(
+	ctx context.Context,
+	job string,
+	buildNumber int,
+	pollInterval, timeout time.Duration,
+) (*BuildInfo, error) {
+	return jenkins.pollBuild(ctx, job, buildNumber, pollInterval, time.Now().Add(timeout))
+}
+
+// pollBuild polls the build status until the build completes or the deadline passes.
+// A timeout or cancellation returns the last known build info together with the error.
+func (jenkins *Jenkins) pollBuild(
+	ctx context.Context,
+	job string,
+	buildNumber int,
+	pollInterval time.Duration,
+	deadline time.Time,
+) (*BuildInfo, error) {
+	slog.Info("waiting for job to complete", "job", job, "build_number", buildNumber)
+
+	var follower *consoleFollower
+	if jenkins.FollowLog {
+		follower = jenkins.newConsoleFollower(job, buildNumber)
+	}
+
+	var stages *stageTracker
+	if jenkins.StageProgress {
+		stages = jenkins.newStageTracker(job, buildNumber)
+	}
+
+	// The build number is known even if fetching the build info never succeeds
+	last := &BuildInfo{Number: buildNumber, Building: true}
 
 	for {
 		if time.Now().After(deadline) {
-			return nil, fmt.Errorf(
-				"timeout waiting for job %s build #%d to complete",
+			return last, fmt.Errorf(
+				"%w waiting for job %s build #%d to complete",
+				errTimeout,
 				job,
 				buildNumber,
 			)
 		}
 
+		if follower != nil {
+			if _, err := follower.poll(ctx); err != nil {
+				slog.Warn("failed to fetch console log", "job", job, "build_number", buildNumber, "error", err)
+			}
+		}
+
+		if stages != nil {
+			if err := stages.poll(ctx); err != nil {
+				slog.Warn("failed to fetch pipeline stages", "job", job, "build_number", buildNumber, "error", err)
+			}
+		}
+
 		buildInfo, err := jenkins.getBuildInfo(ctx, job, buildNumber)
 		if err != nil {
-			log.Printf("warning: failed to get build info: %v", err)
-			time.Sleep(pollInterval)
+			slog.Warn("failed to get build info", "job", job, "build_number", buildNumber, "error", err)
+			if err := sleepContext(ctx, pollInterval); err != nil {
+				return last, err
+			}
 			continue
 		}
+		last = buildInfo
 
 		// Check if build is complete
 		if !buildInfo.Building {
-			log.Printf(
-				"job %s (build #%d) completed with status: %s",
-				job,
-				buildNumber,
-				buildInfo.Result,
-			)
-
-			// Debug: Display final build info
-			if jenkins.Debug {
-				log.Println("=== Debug Mode: Build Result ===")
-				if err := godump.Dump(buildInfo); err != nil {
-					log.Printf("warning: failed to dump build info: %v", err)
+			// Print whatever console output was written after the last poll
+			if follower != nil {
+				if err := follower.drain(ctx); err != nil {
+					slog.Warn("failed to fetch console log", "job", job, "build_number", buildNumber, "error", err)
 				}
-				log.Println("================================")
 			}
 
-			// Set GitHub Actions output
-			if err := gh.SetOutput(map[string]string{
-				"result": buildInfo.Result,
-				"url":    buildInfo.URL,
-			}); err != nil {
-				log.Printf("warning: failed to set GitHub output: %v", err)
+			// Report the final stage statuses, which may have changed since the last poll
+			if stages != nil {
+				if err := stages.poll(ctx); err != nil {
+					slog.Warn(
+						"failed to fetch pipeline stages",
+						"job", job,
+						"build_number", buildNumber,
+						"error", err,
+					)
+				}
+				stages.logSummary()
 			}
 
+			slog.Info(
+				"job completed",
+				"job", job,
+				"build_number", buildNumber,
+				"result", buildInfo.Result,
+				"url", buildInfo.URL,
+			)
+			slog.Debug(
+				"build details",
+				"job", job,
+				"build_number", buildNumber,
+				"duration", time.Duration(buildInfo.Duration)*time.Millisecond,
+				"started_at", time.UnixMilli(buildInfo.Timestamp),
+				"artifacts", len(buildInfo.Artifacts),
+			)
+
 			return buildInfo, nil
 		}
 
-		time.Sleep(pollInterval)
+		if err := sleepContext(ctx, pollInterval); err != nil {
+			return last, err
+		}
 	}
 }
 
-func (jenkins *Jenkins) trigger(ctx context.Context, job string, params url.Values) (int, error) {
-	// Add remote trigger token to params
+// trigger starts a build of the job and returns its queue item ID.
+// Jobs with file parameters, given as parameter name to local path, are
+// triggered with a multipart/form-data request streaming the files.
+func (jenkins *Jenkins) trigger(
+	ctx context.Context,
+	job string,
+	params url.Values,
+	files map[string]string,
+) (int, error) {
+	// Add remote trigger token to a copy of params, which may be shared between jobs
 	if jenkins.Token != "" {
-		if params == nil {
-			params = url.Values{}
-		}
+		params = cloneValues(params)
 		params.Set(tokenParam, jenkins.Token)
 	}
 
 	var urlPath string
 	// Check if params contains build parameters (excluding 'token')
-	hasBuildParams := false
+	hasBuildParams := len(files) > 0
 	for key := range params {
 		if key != tokenParam {
 			hasBuildParams = true
@@ -508,43 +878,51 @@ func (jenkins *Jenkins) trigger(ctx context.Context, job string, params url.Valu
 		urlPath = jenkins.parseJobPath(job) + "/build"
 	}
 
-	// Debug: Display parameters being sent
-	if jenkins.Debug {
-		log.Println("=== Debug Mode: Jenkins Job Trigger ===")
-		log.Printf("Job: %s", job)
-		log.Printf("URL Path: %s", urlPath)
+	// Parameters are sent in the request body so that they don't end up in access logs.
+	// With files, the build parameters are sent in the form along with the files and
+	// the token stays in the query string.
+	var query url.Values
+	var body requestBody
+	switch {
+	case len(files) > 0:
+		query = url.Values{}
+		form := cloneValues(params)
+		if form.Has(tokenParam) {
+			query.Set(tokenParam, form.Get(tokenParam))
+			form.Del(tokenParam)
+		}
+		body = multipartBody(form, files)
+	case jenkins.QueryParams || len(params) == 0:
+		query = params
+	default:
+		body = formBody(params)
+	}
 
-		// Build the full URL for display
-		fullURL := jenkins.buildURL(urlPath, params)
-		// Mask token in URL for display
+	// Debug: Display the request with the token masked
+	if jenkins.Debug {
+		fullURL := jenkins.buildURL(urlPath, query)
 		if jenkins.Token != "" {
 			fullURL = strings.Replace(fullURL, "token="+jenkins.Token, "token=***MASKED***", 1)
 		}
-		log.Printf("Full URL: %s", fullURL)
-
-		if len(params) > 0 {
-			// Create a copy of params with masked token for display
-			displayParams := url.Values{}
-			for key, values := range params {
-				if key == tokenParam {
-					// Mask token values for security
-					displayParams[key] = []string{"***MASKED***"}
-				} else {
-					displayParams[key] = values
-				}
-			}
 
-			log.Println("Parameters:")
-			if err := godump.Dump(displayParams); err != nil {
-				log.Printf("warning: failed to dump parameters: %v", err)
+		displayParams := url.Values{}
+		for key, values := range params {
+			if key == tokenParam {
+				displayParams[key] = []string{"***MASKED***"}
+			} else {
+				displayParams[key] = values
 			}
-		} else {
-			log.Println("Parameters: (none)")
 		}
-		log.Println("======================================")
+
+		slog.Debug(
+			"triggering job",
+			"job", job,
+			"url", fullURL,
+			"parameters", displayParams,
+			"file_parameters", files,
+		)
 	}
 
-	// All params (including token) are passed as query parameters
 	// Returns the queue item ID for tracking
-	return jenkins.postAndGetLocation(ctx, urlPath, params)
+	return jenkins.postAndGetLocation(ctx, urlPath, query, body)
 }
@@ -7,6 +7,8 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -26,6 +28,10 @@ func TestParseJobPath(t *testing.T) {
 		"",
 		false,
 		"",
+		"",
+		"",
+		"",
+		"",
 		false,
 	)
 	assert.NoError(t, err)
@@ -41,46 +47,71 @@ func TestUnSupportProtocol(t *testing.T) {
 		Username: testUserFoo,
 		Token:    testUserBar,
 	}
-	jenkins, err := NewJenkins(context.Background(), auth, "example.com", "", false, "", false)
+	jenkins, err := NewJenkins(context.Background(), auth, "example.com", "", false, "", "", "", "", "", false)
 	assert.NoError(t, err)
 
-	queueID, err := jenkins.trigger(context.Background(), "drone-jenkins", nil)
+	queueID, err := jenkins.trigger(context.Background(), "drone-jenkins", nil, nil)
 	assert.NotNil(t, err)
 	assert.Equal(t, 0, queueID)
 }
 
 func TestTriggerBuild(t *testing.T) {
-	// Create a mock Jenkins server
-	var receivedParams url.Values
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		receivedParams = r.URL.Query()
-		w.Header().Set("Location", "http://jenkins.example.com/queue/item/123/")
-		w.WriteHeader(http.StatusCreated)
-	}))
-	defer server.Close()
-
-	auth := &Auth{
-		Username: testUserFoo,
-		Token:    testUserBar,
+	tests := []struct {
+		name        string
+		queryParams bool
+	}{
+		{name: "request body", queryParams: false},
+		{name: "query string", queryParams: true},
 	}
-	jenkins, err := NewJenkins(
-		context.Background(),
-		auth,
-		server.URL,
-		"remote-token",
-		false,
-		"",
-		false,
-	)
-	assert.NoError(t, err)
 
-	params := url.Values{"param": []string{"value"}}
-	queueID, err := jenkins.trigger(context.Background(), "drone-jenkins", params)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a mock Jenkins server
+			var receivedQuery, receivedForm url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, r.ParseForm())
+				receivedQuery, receivedForm = r.URL.Query(), r.PostForm
+				w.Header().Set("Location", "http://jenkins.example.com/queue/item/123/")
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
 
-	assert.NoError(t, err)
-	assert.Equal(t, 123, queueID)
-	assert.Equal(t, "value", receivedParams.Get("param"))
-	assert.Equal(t, "remote-token", receivedParams.Get("token"))
+			auth := &Auth{
+				Username: testUserFoo,
+				Token:    testUserBar,
+			}
+			jenkins, err := NewJenkins(
+				context.Background(),
+				auth,
+				server.URL,
+				"remote-token",
+				false,
+				"",
+				"",
+				"",
+				"",
+				"",
+				false,
+			)
+			assert.NoError(t, err)
+			jenkins.QueryParams = tt.queryParams
+
+			params := url.Values{"param": []string{"value"}}
+			queueID, err := jenkins.trigger(context.Background(), "drone-jenkins", params, nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 123, queueID)
+
+			// Parameters and the token are sent in one place only
+			sent, other := receivedForm, receivedQuery
+			if tt.queryParams {
+				sent, other = receivedQuery, receivedForm
+			}
+			assert.Equal(t, "value", sent.Get("param"))
+			assert.Equal(t, "remote-token", sent.Get("token"))
+			assert.Empty(t, other)
+		})
+	}
 }
 
 func TestPostAndGetLocation(t *testing.T) {
@@ -132,10 +163,10 @@ func TestPostAndGetLocation(t *testing.T) {
 				Username: testUserName,
 				Token:    testUserName,
 			}
-			jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", false)
+			jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", "", "", "", "", false)
 			assert.NoError(t, err)
 
-			queueID, err := jenkins.postAndGetLocation(context.Background(), "/test", nil)
+			queueID, err := jenkins.postAndGetLocation(context.Background(), "/test", nil, nil)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -147,6 +178,127 @@ func TestPostAndGetLocation(t *testing.T) {
 	}
 }
 
+func TestGetCrumb(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		expectCrumb bool
+		expectError bool
+	}{
+		{
+			name:        "crumb issued",
+			status:      http.StatusOK,
+			body:        `{"crumb":"abc","crumbRequestField":"Jenkins-Crumb"}`,
+			expectCrumb: true,
+		},
+		{name: "csrf disabled", status: http.StatusNotFound},
+		{name: "unauthorized", status: http.StatusUnauthorized, expectError: true},
+		{name: "server error", status: http.StatusInternalServerError, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, "/crumbIssuer/api/json", r.URL.Path)
+					atomic.AddInt32(&calls, 1)
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(tt.body))
+				}),
+			)
+			defer server.Close()
+
+			jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+			assert.NoError(t, err)
+
+			crumb, err := jenkins.getCrumb(context.Background())
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if !tt.expectCrumb {
+				assert.Nil(t, crumb)
+				return
+			}
+			assert.Equal(t, "abc", crumb.Crumb)
+
+			// The crumb is cached for subsequent requests
+			_, err = jenkins.getCrumb(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestPostRefreshesRejectedCrumb(t *testing.T) {
+	tests := []struct {
+		name         string
+		validFrom    int32 // First issued crumb accepted by the server
+		expectError  bool
+		expectCrumbs int32
+		expectPosts  int32
+	}{
+		{name: "expired crumb is refreshed", validFrom: 2, expectCrumbs: 2, expectPosts: 2},
+		{
+			name:         "request is replayed only once",
+			validFrom:    3,
+			expectError:  true,
+			expectCrumbs: 2,
+			expectPosts:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var crumbs, posts int32
+			server := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/crumbIssuer/api/json" {
+						n := atomic.AddInt32(&crumbs, 1)
+						_, _ = w.Write([]byte(
+							`{"crumb":"crumb-` + strconv.Itoa(int(n)) +
+								`","crumbRequestField":"Jenkins-Crumb"}`,
+						))
+						return
+					}
+
+					atomic.AddInt32(&posts, 1)
+					n, _ := strconv.Atoi(strings.TrimPrefix(r.Header.Get("Jenkins-Crumb"), "crumb-"))
+					if int32(n) < tt.validFrom {
+						w.WriteHeader(http.StatusForbidden)
+						_, _ = w.Write([]byte("No valid crumb was included in the request"))
+						return
+					}
+					w.Header().Set("Location", "http://jenkins.example.com/queue/item/123/")
+					w.WriteHeader(http.StatusCreated)
+				}),
+			)
+			defer server.Close()
+
+			auth := &Auth{
+				Username: testUserName,
+				Token:    testUserName,
+			}
+			jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", "", "", "", "", false)
+			assert.NoError(t, err)
+
+			queueID, err := jenkins.postAndGetLocation(context.Background(), "/test", nil, nil)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.True(t, isCrumbRejected(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 123, queueID)
+			}
+			assert.Equal(t, tt.expectCrumbs, atomic.LoadInt32(&crumbs))
+			assert.Equal(t, tt.expectPosts, atomic.LoadInt32(&posts))
+		})
+	}
+}
+
 func TestGetQueueItem(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -209,7 +361,7 @@ func TestGetQueueItem(t *testing.T) {
 				Username: testUserName,
 				Token:    testUserName,
 			}
-			jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", false)
+			jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", "", "", "", "", false)
 			assert.NoError(t, err)
 
 			queueItem, err := jenkins.getQueueItem(context.Background(), tt.queueID)
@@ -298,7 +450,7 @@ func TestGetBuildInfo(t *testing.T) {
 				Username: testUserName,
 				Token:    testUserName,
 			}
-			jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", false)
+			jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", "", "", "", "", false)
 			assert.NoError(t, err)
 
 			buildInfo, err := jenkins.getBuildInfo(context.Background(), tt.jobName, tt.buildNumber)
@@ -361,7 +513,7 @@ func TestWaitForCompletion(t *testing.T) {
 			Username: testUserName,
 			Token:    testUserName,
 		}
-		jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", false)
+		jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", "", "", "", "", false)
 		assert.NoError(t, err)
 
 		buildInfo, err := jenkins.waitForCompletion(
@@ -395,7 +547,7 @@ func TestWaitForCompletion(t *testing.T) {
 			Username: testUserName,
 			Token:    testUserName,
 		}
-		jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", false)
+		jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", "", "", "", "", false)
 		assert.NoError(t, err)
 
 		buildInfo, err := jenkins.waitForCompletion(
@@ -437,7 +589,7 @@ func TestWaitForCompletion(t *testing.T) {
 			Username: testUserName,
 			Token:    testUserName,
 		}
-		jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", false)
+		jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", "", "", "", "", false)
 		assert.NoError(t, err)
 
 		buildInfo, err := jenkins.waitForCompletion(
@@ -449,8 +601,11 @@ func TestWaitForCompletion(t *testing.T) {
 		)
 
 		assert.Error(t, err)
-		assert.Nil(t, buildInfo)
 		assert.Contains(t, err.Error(), "timeout")
+		// The last known build info is kept, so the build can be reported
+		assert.NotNil(t, buildInfo)
+		assert.Equal(t, 456, buildInfo.Number)
+		assert.True(t, buildInfo.Building)
 	})
 
 	t.Run("build failed", func(t *testing.T) {
@@ -488,7 +643,7 @@ func TestWaitForCompletion(t *testing.T) {
 			Username: testUserName,
 			Token:    testUserName,
 		}
-		jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", false)
+		jenkins, err := NewJenkins(context.Background(), auth, server.URL, "", false, "", "", "", "", "", false)
 		assert.NoError(t, err)
 
 		buildInfo, err := jenkins.waitForCompletion(
@@ -530,20 +685,20 @@ ud3vS1A5+g==
 
 func TestLoadCACert(t *testing.T) {
 	t.Run("empty string returns nil", func(t *testing.T) {
-		data, err := loadCACert(context.Background(), "")
+		data, err := loadCACert(context.Background(), http.DefaultClient, "")
 		assert.NoError(t, err)
 		assert.Nil(t, data)
 	})
 
 	t.Run("PEM content directly", func(t *testing.T) {
-		data, err := loadCACert(context.Background(), testCACert)
+		data, err := loadCACert(context.Background(), http.DefaultClient, testCACert)
 		assert.NoError(t, err)
 		assert.NotNil(t, data)
 		assert.Contains(t, string(data), "BEGIN CERTIFICATE")
 	})
 
 	t.Run("PEM content with leading whitespace", func(t *testing.T) {
-		data, err := loadCACert(context.Background(), "  \n"+testCACert)
+		data, err := loadCACert(context.Background(), http.DefaultClient, "  \n"+testCACert)
 		assert.NoError(t, err)
 		assert.NotNil(t, data)
 		assert.Contains(t, string(data), "BEGIN CERTIFICATE")
@@ -556,14 +711,14 @@ func TestLoadCACert(t *testing.T) {
 		err := os.WriteFile(certFile, []byte(testCACert), 0o600)
 		assert.NoError(t, err)
 
-		data, err := loadCACert(context.Background(), certFile)
+		data, err := loadCACert(context.Background(), http.DefaultClient, certFile)
 		assert.NoError(t, err)
 		assert.NotNil(t, data)
 		assert.Contains(t, string(data), "BEGIN CERTIFICATE")
 	})
 
 	t.Run("file not found", func(t *testing.T) {
-		data, err := loadCACert(context.Background(), "/nonexistent/path/ca.pem")
+		data, err := loadCACert(context.Background(), http.DefaultClient, "/nonexistent/path/ca.pem")
 		assert.Error(t, err)
 		assert.Nil(t, data)
 		assert.Contains(t, err.Error(), "failed to read CA certificate file")
@@ -576,7 +731,7 @@ func TestLoadCACert(t *testing.T) {
 		}))
 		defer server.Close()
 
-		data, err := loadCACert(context.Background(), server.URL)
+		data, err := loadCACert(context.Background(), http.DefaultClient, server.URL)
 		assert.NoError(t, err)
 		assert.NotNil(t, data)
 		assert.Contains(t, string(data), "BEGIN CERTIFICATE")
@@ -594,7 +749,7 @@ func TestLoadCACert(t *testing.T) {
 		// Note: This test uses the test server's self-signed cert
 		// In real scenarios, the URL would be to a trusted source
 		// We skip HTTPS verification for this test
-		data, err := loadCACert(context.Background(), server.URL)
+		data, err := loadCACert(context.Background(), http.DefaultClient, server.URL)
 		// This may fail due to certificate verification, which is expected
 		if err != nil {
 			assert.Contains(t, err.Error(), "certificate")
@@ -609,14 +764,14 @@ func TestLoadCACert(t *testing.T) {
 		}))
 		defer server.Close()
 
-		data, err := loadCACert(context.Background(), server.URL)
+		data, err := loadCACert(context.Background(), http.DefaultClient, server.URL)
 		assert.Error(t, err)
 		assert.Nil(t, data)
 		assert.Contains(t, err.Error(), "HTTP 404")
 	})
 
 	t.Run("HTTP URL unreachable", func(t *testing.T) {
-		data, err := loadCACert(context.Background(), "http://localhost:59999/nonexistent")
+		data, err := loadCACert(context.Background(), http.DefaultClient, "http://localhost:59999/nonexistent")
 		assert.Error(t, err)
 		assert.Nil(t, data)
 		assert.Contains(t, err.Error(), "failed to fetch CA certificate from URL")
@@ -636,6 +791,10 @@ func TestNewJenkinsWithCACert(t *testing.T) {
 			"",
 			false,
 			testCACert,
+			"",
+			"",
+			"",
+			"",
 			false,
 		)
 		assert.NoError(t, err)
@@ -660,6 +819,10 @@ func TestNewJenkinsWithCACert(t *testing.T) {
 			"",
 			false,
 			certFile,
+			"",
+			"",
+			"",
+			"",
 			false,
 		)
 		assert.NoError(t, err)
@@ -678,6 +841,10 @@ func TestNewJenkinsWithCACert(t *testing.T) {
 			"",
 			false,
 			"invalid-cert-data",
+			"",
+			"",
+			"",
+			"",
 			false,
 		)
 		assert.Error(t, err)
@@ -698,6 +865,10 @@ func TestNewJenkinsWithCACert(t *testing.T) {
 			"",
 			false,
 			invalidPEM,
+			"",
+			"",
+			"",
+			"",
 			false,
 		)
 		assert.Error(t, err)
@@ -717,6 +888,10 @@ func TestNewJenkinsWithCACert(t *testing.T) {
 			"",
 			false,
 			"/nonexistent/ca.pem",
+			"",
+			"",
+			"",
+			"",
 			false,
 		)
 		assert.Error(t, err)
@@ -737,6 +912,10 @@ func TestNewJenkinsWithCACert(t *testing.T) {
 			"",
 			true,
 			testCACert,
+			"",
+			"",
+			"",
+			"",
 			false,
 		)
 		assert.NoError(t, err)
@@ -755,6 +934,10 @@ func TestNewJenkinsWithCACert(t *testing.T) {
 			"",
 			false,
 			"",
+			"",
+			"",
+			"",
+			"",
 			false,
 		)
 		assert.NoError(t, err)
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// JobConfig is an entry of the structured jobs setting
+	JobConfig struct {
+		Name       string        `yaml:"name"`
+		Parameters jobParameters `yaml:"parameters"` // Merged over the shared parameters
+		Wait       *bool         `yaml:"wait"`       // Overrides the global wait setting
+		Timeout    jobDuration   `yaml:"timeout"`    // Overrides the global timeout
+	}
+
+	// jobParameters holds the build parameters of a job, given either as a
+	// mapping of names to a value or a list of values, or as key=value lines
+	jobParameters url.Values
+
+	// jobDuration is a duration given as a string such as 45m
+	jobDuration time.Duration
+)
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (p *jobParameters) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			*p = nil
+			return nil
+		}
+		*p = jobParameters(parseParameters(node.Value))
+		return nil
+	case yaml.MappingNode:
+		values := url.Values{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			switch {
+			case value.Tag == "!!null":
+				values.Add(key, "")
+			case value.Kind == yaml.ScalarNode:
+				values.Add(key, value.Value)
+			case value.Kind == yaml.SequenceNode:
+				for _, item := range value.Content {
+					if item.Kind != yaml.ScalarNode {
+						return fmt.Errorf("line %d: parameter %q must be a list of values", item.Line, key)
+					}
+					values.Add(key, item.Value)
+				}
+			default:
+				return fmt.Errorf("line %d: parameter %q must be a value or a list", value.Line, key)
+			}
+		}
+		*p = jobParameters(values)
+		return nil
+	default:
+		return fmt.Errorf("line %d: parameters must be a mapping or key=value lines", node.Line)
+	}
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (d *jobDuration) UnmarshalYAML(node *yaml.Node) error {
+	duration, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid timeout %q: %w", node.Line, node.Value, err)
+	}
+	*d = jobDuration(duration)
+
+	return nil
+}
+
+// parseJobConfigs parses the structured jobs setting, a JSON or YAML list of job objects
+func parseJobConfigs(input string) ([]JobConfig, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(input))
+	decoder.KnownFields(true)
+
+	var configs []JobConfig
+	if err := decoder.Decode(&configs); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid jobs setting: %w", err)
+	}
+
+	for i := range configs {
+		configs[i].Name = strings.TrimSpace(configs[i].Name)
+		if configs[i].Name == "" {
+			return nil, fmt.Errorf("invalid jobs setting: entry %d has no name", i+1)
+		}
+	}
+
+	return configs, nil
+}
+
+// mergeJobs combines the flat job list with the structured job configs,
+// returning all job names in order and the configs by job name
+func mergeJobs(jobs []string, configs []JobConfig) ([]string, map[string]*JobConfig, error) {
+	all := append([]string(nil), jobs...)
+	byName := make(map[string]*JobConfig, len(configs))
+
+	seen := make(map[string]bool, len(jobs)+len(configs))
+	for _, job := range jobs {
+		seen[job] = true
+	}
+
+	for i := range configs {
+		name := configs[i].Name
+		if seen[name] {
+			return nil, nil, fmt.Errorf("job %q is configured more than once", name)
+		}
+		seen[name] = true
+		all = append(all, name)
+		byName[name] = &configs[i]
+	}
+
+	return all, byName, nil
+}
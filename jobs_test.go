@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJobConfigs(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		configs, err := parseJobConfigs("  \n")
+		assert.NoError(t, err)
+		assert.Nil(t, configs)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		configs, err := parseJobConfigs(`[
+			{"name": "deploy-api", "parameters": {"SERVICE": "api", "REPLICAS": 3}, "timeout": "45m"},
+			{"name": "deploy-web", "wait": false}
+		]`)
+		assert.NoError(t, err)
+		assert.Len(t, configs, 2)
+		assert.Equal(t, "deploy-api", configs[0].Name)
+		assert.Equal(t, jobParameters{"SERVICE": {"api"}, "REPLICAS": {"3"}}, configs[0].Parameters)
+		assert.Equal(t, jobDuration(45*time.Minute), configs[0].Timeout)
+		assert.Nil(t, configs[0].Wait)
+		assert.False(t, *configs[1].Wait)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		configs, err := parseJobConfigs(`
+- name: folder/deploy-api
+  parameters:
+    SERVICE: api
+    TAGS: [blue, green]
+- name: deploy-web
+  parameters: |
+    SERVICE=web
+    REPLICAS=2
+`)
+		assert.NoError(t, err)
+		assert.Len(t, configs, 2)
+		assert.Equal(t, "folder/deploy-api", configs[0].Name)
+		assert.Equal(t, []string{"blue", "green"}, url.Values(configs[0].Parameters)["TAGS"])
+		assert.Equal(t, jobParameters{"SERVICE": {"web"}, "REPLICAS": {"2"}}, configs[1].Parameters)
+	})
+
+	tests := []struct {
+		name   string
+		input  string
+		errMsg string
+	}{
+		{name: "not a list", input: `{"name": "deploy"}`, errMsg: "invalid jobs setting"},
+		{name: "missing name", input: `[{"wait": true}]`, errMsg: "entry 1 has no name"},
+		{name: "unknown field", input: `[{"name": "deploy", "param": {}}]`, errMsg: "field param not found"},
+		{name: "invalid timeout", input: `[{"name": "deploy", "timeout": "soon"}]`, errMsg: `invalid timeout "soon"`},
+		{
+			name:   "nested parameter",
+			input:  `[{"name": "deploy", "parameters": {"A": {"B": "C"}}}]`,
+			errMsg: `parameter "A" must be a value or a list`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJobConfigs(tt.input)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestMergeJobs(t *testing.T) {
+	configs := []JobConfig{{Name: "deploy-api"}, {Name: "deploy-web"}}
+
+	jobs, byName, err := mergeJobs([]string{"build"}, configs)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"build", "deploy-api", "deploy-web"}, jobs)
+	assert.Equal(t, "deploy-web", byName["deploy-web"].Name)
+	assert.NotContains(t, byName, "build")
+
+	_, _, err = mergeJobs([]string{"deploy-api"}, configs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `job "deploy-api" is configured more than once`)
+}
+
+// TestExecStructuredJobs tests triggering jobs with their own parameters and wait settings
+func TestExecStructuredJobs(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]url.Values{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.Method == http.MethodPost:
+			mu.Lock()
+			assert.NoError(t, r.ParseForm())
+			received[parts[1]] = r.PostForm
+			mu.Unlock()
+			w.Header().Set("Location", "http://jenkins.example.com/queue/item/123/")
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == testQueueItemPath:
+			_, _ = w.Write([]byte(`{"id":123,"executable":{"number":456}}`))
+		case len(parts) == 5 && parts[3] == "api":
+			_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"SUCCESS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	plugin := Plugin{
+		BaseURL:    server.URL,
+		Username:   testUserFoo,
+		Token:      testUserBar,
+		Job:        []string{"build"},
+		Parameters: "BRANCH=main\nSERVICE=all",
+		Jobs: `
+- name: deploy-api
+  parameters:
+    SERVICE: api
+    REPLICAS: 3
+- name: deploy-web
+  wait: true
+  timeout: 1m
+  parameters:
+    SERVICE: web
+`,
+		PollInterval: 10 * time.Millisecond,
+	}
+
+	err := plugin.Exec(context.Background())
+	assert.NoError(t, err)
+
+	// Shared parameters are overridden per job
+	assert.Equal(t, "all", received["build"].Get("SERVICE"))
+	assert.Equal(t, "api", received["deploy-api"].Get("SERVICE"))
+	assert.Equal(t, "3", received["deploy-api"].Get("REPLICAS"))
+	assert.Equal(t, "main", received["deploy-api"].Get("BRANCH"))
+	assert.Equal(t, "web", received["deploy-web"].Get("SERVICE"))
+	assert.Empty(t, received["deploy-web"].Get("REPLICAS"))
+
+	t.Run("per job wait overrides global", func(t *testing.T) {
+		r := &runner{
+			wait: true,
+			jobs: map[string]*JobConfig{"skip": {Name: "skip", Wait: new(bool)}},
+		}
+		assert.False(t, r.jobWait("skip"))
+		assert.True(t, r.jobWait("other"))
+
+		r.forceWait = true
+		assert.True(t, r.jobWait("skip"))
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Log formats
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// sensitiveLogKeys are the attribute keys whose values are masked in log records
+var sensitiveLogKeys = map[string]bool{
+	"token":         true,
+	"remote_token":  true,
+	"password":      true,
+	"secret":        true,
+	"client_secret": true,
+	"client_key":    true,
+	"passphrase":    true,
+	"authorization": true,
+	"crumb":         true,
+}
+
+// newLogger creates a leveled logger writing text or JSON records to w.
+// Debug records are only written in debug mode.
+func newLogger(w io.Writer, format string, debug bool) (*slog.Logger, error) {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceAttr,
+	}
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", logFormatText:
+		return slog.New(newTableHandler(slog.NewTextHandler(w, opts), w, true)), nil
+	case logFormatJSON:
+		return slog.New(newTableHandler(slog.NewJSONHandler(w, opts), w, false)), nil
+	default:
+		return nil, fmt.Errorf(
+			"unknown log format %q (expected %s or %s)",
+			format,
+			logFormatText,
+			logFormatJSON,
+		)
+	}
+}
+
+// replaceAttr masks secrets and writes durations in a readable form
+// rather than as nanoseconds in JSON records
+func replaceAttr(groups []string, attr slog.Attr) slog.Attr {
+	attr = maskSecrets(groups, attr)
+	if attr.Value.Kind() == slog.KindDuration {
+		return slog.String(attr.Key, attr.Value.Duration().String())
+	}
+
+	return attr
+}
+
+// maskSecrets masks the values of sensitive attributes, so secrets
+// never reach the log even if they are logged by mistake
+func maskSecrets(_ []string, attr slog.Attr) slog.Attr {
+	if sensitiveLogKeys[strings.ToLower(attr.Key)] && attr.Value.String() != "" {
+		return slog.String(attr.Key, maskToken(attr.Value.String()))
+	}
+
+	return attr
+}
+
+// logTable is a log attribute value holding the rows of a result table
+type logTable struct {
+	columns []string // Attribute keys of the row values
+	rows    [][]any  // Row values in column order, nil if a value is not known
+}
+
+// String renders the table with aligned columns and an upper-case header
+func (t logTable) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(t.columns, "\t")))
+	for _, row := range t.rows {
+		values := make([]string, len(row))
+		for i, value := range row {
+			switch v := value.(type) {
+			case nil:
+				values[i] = "-"
+			case time.Duration:
+				values[i] = v.String()
+			default:
+				values[i] = fmt.Sprint(v)
+			}
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	_ = w.Flush()
+
+	return b.String()
+}
+
+// tableHandler writes logTable attributes in a form suited to the wrapped handler:
+// text records are followed by the rendered table, while JSON records are repeated
+// once per row with the row values as attributes
+type tableHandler struct {
+	slog.Handler
+	w    io.Writer
+	mu   *sync.Mutex
+	text bool
+}
+
+// newTableHandler wraps h, which writes to w, with support for logTable attributes
+func newTableHandler(h slog.Handler, w io.Writer, text bool) *tableHandler {
+	return &tableHandler{Handler: h, w: w, mu: &sync.Mutex{}, text: text}
+}
+
+// Handle writes the record, expanding a logTable attribute if it has one
+func (h *tableHandler) Handle(ctx context.Context, r slog.Record) error {
+	var table *logTable
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		if t, ok := attr.Value.Any().(logTable); ok {
+			table = &t
+		} else {
+			record.AddAttrs(attr)
+		}
+		return true
+	})
+
+	// Hold the lock for every record, so no other record ends up between a record and its table
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if table == nil || (!h.text && len(table.rows) == 0) {
+		return h.Handler.Handle(ctx, record)
+	}
+
+	if h.text {
+		if err := h.Handler.Handle(ctx, record); err != nil {
+			return err
+		}
+		_, err := io.WriteString(h.w, table.String())
+		return err
+	}
+
+	for _, row := range table.rows {
+		rowRecord := record.Clone()
+		for i, value := range row {
+			if value != nil && i < len(table.columns) {
+				rowRecord.AddAttrs(slog.Any(table.columns[i], value))
+			}
+		}
+		if err := h.Handler.Handle(ctx, rowRecord); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WithAttrs returns a table handler wrapping the handler with the attributes
+func (h *tableHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &tableHandler{Handler: h.Handler.WithAttrs(attrs), w: h.w, mu: h.mu, text: h.text}
+}
+
+// WithGroup returns a table handler wrapping the handler with the group
+func (h *tableHandler) WithGroup(name string) slog.Handler {
+	return &tableHandler{Handler: h.Handler.WithGroup(name), w: h.w, mu: h.mu, text: h.text}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := newLogger(&buf, "", false)
+		assert.NoError(t, err)
+
+		logger.Debug("hidden")
+		logger.Info("job started", "job", testJobName, "queue_id", 123, "build_number", 456)
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Contains(t, buf.String(), `level=INFO msg="job started" job=test-job queue_id=123 build_number=456`)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := newLogger(&buf, " JSON ", true)
+		assert.NoError(t, err)
+
+		logger.Debug(
+			"obtained crumb",
+			"crumb", "abc",
+			"token", testRemoteTokenValue,
+			"job", testJobName,
+			"wait", 1500*time.Millisecond,
+		)
+
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "DEBUG", record["level"])
+		assert.Equal(t, "obtained crumb", record["msg"])
+		assert.Equal(t, testJobName, record["job"])
+		assert.Equal(t, "***MASKED***", record["crumb"])
+		assert.Equal(t, "***MASKED***", record["token"])
+		assert.Equal(t, "1.5s", record["wait"])
+		assert.NotContains(t, buf.String(), testRemoteTokenValue)
+	})
+
+	t.Run("text table", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := newLogger(&buf, "text", false)
+		assert.NoError(t, err)
+
+		logger.Info("job graph results", "jobs", logTable{
+			columns: []string{"job", "status", "build_number"},
+			rows:    [][]any{{"build", "succeeded", 12}, {"deploy", "skipped", nil}},
+		})
+		assert.Contains(t, buf.String(), "level=INFO msg=\"job graph results\"\n"+
+			"JOB     STATUS     BUILD_NUMBER\n"+
+			"build   succeeded  12\n"+
+			"deploy  skipped    -\n")
+	})
+
+	t.Run("json table", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := newLogger(&buf, "json", false)
+		assert.NoError(t, err)
+
+		logger.Info("stage results", "job", testJobName, "stages", logTable{
+			columns: []string{"stage", "result", "duration"},
+			rows:    [][]any{{"Build", "SUCCESS", 2 * time.Second}, {"Deploy", nil, nil}},
+		})
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		assert.Len(t, lines, 2)
+		var build, deploy map[string]interface{}
+		assert.NoError(t, json.Unmarshal(lines[0], &build))
+		assert.NoError(t, json.Unmarshal(lines[1], &deploy))
+		assert.Equal(t, "stage results", build["msg"])
+		assert.Equal(t, testJobName, build["job"])
+		assert.Equal(t, "Build", build["stage"])
+		assert.Equal(t, "SUCCESS", build["result"])
+		assert.Equal(t, "2s", build["duration"])
+		assert.Equal(t, "Deploy", deploy["stage"])
+		assert.NotContains(t, deploy, "result")
+		assert.NotContains(t, deploy, "stages")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := newLogger(&bytes.Buffer{}, "xml", false)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown log format "xml"`)
+	})
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/urfave/cli/v2"
-	"github.com/yassinebenaid/godump"
 )
 
 // Version set at compile-time
@@ -36,13 +39,13 @@ func main() {
 	// Load env-file if it exists first
 	if filename, found := os.LookupEnv("PLUGIN_ENV_FILE"); found {
 		if err := godotenv.Load(filename); err != nil && !os.IsNotExist(err) {
-			log.Printf("Warning: failed to load env file %s: %v", filename, err)
+			slog.Warn("failed to load env file", "path", filename, "error", err)
 		}
 	}
 
 	if _, err := os.Stat("/run/drone/env"); err == nil {
 		if err := godotenv.Overload("/run/drone/env"); err != nil {
-			log.Printf("Warning: failed to load /run/drone/env: %v", err)
+			slog.Warn("failed to load env file", "path", "/run/drone/env", "error", err)
 		}
 	}
 
@@ -56,9 +59,66 @@ func main() {
 			Email: "appleboy.tw@gmail.com",
 		},
 	}
+	app.Before = setupLogger
 	app.Action = run
+	app.Commands = commands()
 	app.Version = Version
-	app.Flags = []cli.Flag{
+	app.Flags = flags()
+
+	// Override a template
+	cli.AppHelpTemplate = asciiArt + `
+NAME:
+   {{.Name}} - {{.Usage}}
+
+USAGE:
+   {{.HelpName}} {{if .VisibleFlags}}[global options]{{end}}{{if .Commands}} command [command options]{{end}} ` +
+		`{{if .ArgsUsage}}{{.ArgsUsage}}{{else}}[arguments...]{{end}}
+   {{if len .Authors}}
+AUTHOR:
+   {{range .Authors}}{{ . }}{{end}}
+   {{end}}{{if .Commands}}
+COMMANDS:
+{{range .Commands}}{{if not .HideHelp}}   {{join .Names ", "}}{{ "\t"}}{{.Usage}}{{ "\n" }}{{end}}{{end}}{{end}}` +
+		`{{if .VisibleFlags}}
+GLOBAL OPTIONS:
+   {{range .VisibleFlags}}{{.}}
+   {{end}}{{end}}{{if .Copyright }}
+COPYRIGHT:
+   {{.Copyright}}
+   {{end}}{{if .Version}}
+VERSION:
+   {{.Version}}
+   {{end}}
+REPOSITORY:
+    Github: https://github.com/appleboy/drone-jenkins
+`
+
+	// Cancel the context on SIGINT/SIGTERM so running builds can be aborted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := app.RunContext(ctx, os.Args)
+	stop()
+
+	if err != nil {
+		slog.Error("jenkins plugin failed", "error", err)
+		// Exit with a distinct code per Jenkins build result
+		os.Exit(exitCode(err))
+	}
+}
+
+// setupLogger installs the default logger for the selected format and debug mode
+func setupLogger(c *cli.Context) error {
+	logger, err := newLogger(c.App.ErrWriter, c.String("log-format"), c.Bool("debug"))
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	return nil
+}
+
+// flags returns the global flags of the plugin
+func flags() []cli.Flag {
+	return []cli.Flag{
 		&cli.StringFlag{
 			Name:    "host",
 			Usage:   "jenkins base url",
@@ -81,12 +141,60 @@ func main() {
 			Usage:   "jenkins remote trigger token",
 			EnvVars: []string{"PLUGIN_REMOTE_TOKEN", "JENKINS_REMOTE_TOKEN", "INPUT_REMOTE_TOKEN"},
 		},
+		&cli.StringFlag{
+			Name:    "auth-mode",
+			Usage:   "authentication mode: basic (user + token), bearer (token as bearer token) or oauth2",
+			Value:   authBasic,
+			EnvVars: []string{"PLUGIN_AUTH_MODE", "JENKINS_AUTH_MODE", "INPUT_AUTH_MODE"},
+		},
+		&cli.StringFlag{
+			Name:  "oauth-token-url",
+			Usage: "OAuth2 token endpoint for the client credentials grant",
+			EnvVars: []string{
+				"PLUGIN_OAUTH_TOKEN_URL",
+				"JENKINS_OAUTH_TOKEN_URL",
+				"INPUT_OAUTH_TOKEN_URL",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "oauth-client-id",
+			Usage: "OAuth2 client ID",
+			EnvVars: []string{
+				"PLUGIN_OAUTH_CLIENT_ID",
+				"JENKINS_OAUTH_CLIENT_ID",
+				"INPUT_OAUTH_CLIENT_ID",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "oauth-client-secret",
+			Usage: "OAuth2 client secret",
+			EnvVars: []string{
+				"PLUGIN_OAUTH_CLIENT_SECRET",
+				"JENKINS_OAUTH_CLIENT_SECRET",
+				"INPUT_OAUTH_CLIENT_SECRET",
+			},
+		},
+		&cli.StringSliceFlag{
+			Name:    "oauth-scopes",
+			Usage:   "OAuth2 scopes to request",
+			EnvVars: []string{"PLUGIN_OAUTH_SCOPES", "JENKINS_OAUTH_SCOPES", "INPUT_OAUTH_SCOPES"},
+		},
+		&cli.StringFlag{
+			Name:    "headers",
+			Usage:   "extra request headers (multi-line format: Name: value, one per line)",
+			EnvVars: []string{"PLUGIN_HEADERS", "JENKINS_HEADERS", "INPUT_HEADERS"},
+		},
 		&cli.StringSliceFlag{
 			Name:    "job",
 			Aliases: []string{"j"},
 			Usage:   "jenkins job",
 			EnvVars: []string{"PLUGIN_JOB", "JENKINS_JOB", "INPUT_JOB"},
 		},
+		&cli.StringFlag{
+			Name:    "jobs",
+			Usage:   "JSON or YAML list of jobs with their own name, parameters, wait and timeout",
+			EnvVars: []string{"PLUGIN_JOBS", "JENKINS_JOBS", "INPUT_JOBS"},
+		},
 		&cli.BoolFlag{
 			Name:    "insecure",
 			Usage:   "allow insecure server connections when using SSL",
@@ -97,12 +205,81 @@ func main() {
 			Usage:   "custom CA certificate (PEM content, file path, or HTTP URL)",
 			EnvVars: []string{"PLUGIN_CA_CERT", "JENKINS_CA_CERT", "INPUT_CA_CERT"},
 		},
+		&cli.StringFlag{
+			Name:  "client-cert",
+			Usage: "client certificate for mutual TLS (PEM content, file path, or HTTP URL)",
+			EnvVars: []string{
+				"PLUGIN_CLIENT_CERT",
+				"JENKINS_CLIENT_CERT",
+				"INPUT_CLIENT_CERT",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "client-key",
+			Usage: "private key of the client certificate (PEM content, file path, or HTTP URL)",
+			EnvVars: []string{
+				"PLUGIN_CLIENT_KEY",
+				"JENKINS_CLIENT_KEY",
+				"INPUT_CLIENT_KEY",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "client-key-passphrase",
+			Usage: "passphrase of an encrypted client key",
+			EnvVars: []string{
+				"PLUGIN_CLIENT_KEY_PASSPHRASE",
+				"JENKINS_CLIENT_KEY_PASSPHRASE",
+				"INPUT_CLIENT_KEY_PASSPHRASE",
+			},
+		},
+		&cli.StringFlag{
+			Name:    "proxy",
+			Usage:   "http, https or socks5 proxy URL (default: HTTP_PROXY, HTTPS_PROXY and NO_PROXY)",
+			EnvVars: []string{"PLUGIN_PROXY", "JENKINS_PROXY", "INPUT_PROXY"},
+		},
 		&cli.StringFlag{
 			Name:    "parameters",
 			Aliases: []string{"p"},
 			Usage:   "jenkins build parameters (multi-line format: key=value, one per line)",
 			EnvVars: []string{"PLUGIN_PARAMETERS", "JENKINS_PARAMETERS", "INPUT_PARAMETERS"},
 		},
+		&cli.StringFlag{
+			Name:  "parameters-file",
+			Usage: "JSON, YAML or .env file of build parameters, overridden by inline parameters",
+			EnvVars: []string{
+				"PLUGIN_PARAMETERS_FILE",
+				"JENKINS_PARAMETERS_FILE",
+				"INPUT_PARAMETERS_FILE",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "file-parameters",
+			Usage: "files to upload as file parameters in name=path format (one per line)",
+			EnvVars: []string{
+				"PLUGIN_FILE_PARAMETERS",
+				"JENKINS_FILE_PARAMETERS",
+				"INPUT_FILE_PARAMETERS",
+			},
+		},
+		&cli.BoolFlag{
+			Name:  "query-parameters",
+			Usage: "send build parameters and the remote token in the query string, as older setups expect",
+			EnvVars: []string{
+				"PLUGIN_QUERY_PARAMETERS",
+				"JENKINS_QUERY_PARAMETERS",
+				"INPUT_QUERY_PARAMETERS",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "validate-parameters",
+			Usage: "check parameters against the job's parameter definitions before triggering (off, warn, fail)",
+			Value: "off",
+			EnvVars: []string{
+				"PLUGIN_VALIDATE_PARAMETERS",
+				"JENKINS_VALIDATE_PARAMETERS",
+				"INPUT_VALIDATE_PARAMETERS",
+			},
+		},
 		&cli.BoolFlag{
 			Name:    "wait",
 			Usage:   "wait for job completion",
@@ -124,116 +301,323 @@ func main() {
 			Value:   30 * time.Minute,
 			EnvVars: []string{"PLUGIN_TIMEOUT", "JENKINS_TIMEOUT", "INPUT_TIMEOUT"},
 		},
+		&cli.StringFlag{
+			Name:    "depends-on",
+			Usage:   "job dependencies (multi-line format: job=upstream1,upstream2, one per line)",
+			EnvVars: []string{"PLUGIN_DEPENDS_ON", "JENKINS_DEPENDS_ON", "INPUT_DEPENDS_ON"},
+		},
+		&cli.BoolFlag{
+			Name:    "parallel",
+			Usage:   "trigger and wait for multiple jobs concurrently",
+			EnvVars: []string{"PLUGIN_PARALLEL", "JENKINS_PARALLEL", "INPUT_PARALLEL"},
+		},
+		&cli.IntFlag{
+			Name:    "max-parallel",
+			Usage:   "maximum number of jobs running at once in parallel mode (0: unlimited)",
+			EnvVars: []string{"PLUGIN_MAX_PARALLEL", "JENKINS_MAX_PARALLEL", "INPUT_MAX_PARALLEL"},
+		},
+		&cli.BoolFlag{
+			Name:    "follow-log",
+			Usage:   "stream the jenkins console log while waiting for job completion",
+			EnvVars: []string{"PLUGIN_FOLLOW_LOG", "JENKINS_FOLLOW_LOG", "INPUT_FOLLOW_LOG"},
+		},
+		&cli.BoolFlag{
+			Name:  "stage-progress",
+			Usage: "log pipeline stage transitions and a per-stage summary while waiting",
+			EnvVars: []string{
+				"PLUGIN_STAGE_PROGRESS",
+				"JENKINS_STAGE_PROGRESS",
+				"INPUT_STAGE_PROGRESS",
+			},
+		},
+		&cli.BoolFlag{
+			Name:  "abort-on-cancel",
+			Usage: "abort the jenkins build when the plugin is cancelled or times out",
+			EnvVars: []string{
+				"PLUGIN_ABORT_ON_CANCEL",
+				"JENKINS_ABORT_ON_CANCEL",
+				"INPUT_ABORT_ON_CANCEL",
+			},
+		},
+		&cli.StringSliceFlag{
+			Name:    "accept-results",
+			Usage:   "build results treated as success (SUCCESS, UNSTABLE, FAILURE, NOT_BUILT, ABORTED)",
+			Value:   cli.NewStringSlice(resultSuccess),
+			EnvVars: []string{"PLUGIN_ACCEPT_RESULTS", "JENKINS_ACCEPT_RESULTS", "INPUT_ACCEPT_RESULTS"},
+		},
+		&cli.StringFlag{
+			Name:  "job-accept-results",
+			Usage: "per-job accepted build results (multi-line format: job=RESULT1,RESULT2, one per line)",
+			EnvVars: []string{
+				"PLUGIN_JOB_ACCEPT_RESULTS",
+				"JENKINS_JOB_ACCEPT_RESULTS",
+				"INPUT_JOB_ACCEPT_RESULTS",
+			},
+		},
+		&cli.BoolFlag{
+			Name:    "test-report",
+			Usage:   "fetch and summarise the junit test report after waiting for job completion",
+			EnvVars: []string{"PLUGIN_TEST_REPORT", "JENKINS_TEST_REPORT", "INPUT_TEST_REPORT"},
+		},
+		&cli.StringSliceFlag{
+			Name:    "artifacts",
+			Usage:   "glob patterns of build artifacts to download after the job succeeds",
+			EnvVars: []string{"PLUGIN_ARTIFACTS", "JENKINS_ARTIFACTS", "INPUT_ARTIFACTS"},
+		},
+		&cli.StringFlag{
+			Name:    "artifacts-dir",
+			Usage:   "directory to download build artifacts into",
+			Value:   ".",
+			EnvVars: []string{"PLUGIN_ARTIFACTS_DIR", "JENKINS_ARTIFACTS_DIR", "INPUT_ARTIFACTS_DIR"},
+		},
+		&cli.BoolFlag{
+			Name:  "verify-artifacts",
+			Usage: "verify downloaded artifacts against jenkins MD5 fingerprints",
+			EnvVars: []string{
+				"PLUGIN_VERIFY_ARTIFACTS",
+				"JENKINS_VERIFY_ARTIFACTS",
+				"INPUT_VERIFY_ARTIFACTS",
+			},
+		},
+		&cli.StringFlag{
+			Name:    "state-file",
+			Usage:   "JSON file recording the triggered jobs, used to resume waiting in a later step",
+			EnvVars: []string{"PLUGIN_STATE_FILE", "JENKINS_STATE_FILE", "INPUT_STATE_FILE"},
+		},
+		&cli.BoolFlag{
+			Name:    "resume",
+			Usage:   "wait for the jobs recorded in the state file instead of triggering new builds",
+			EnvVars: []string{"PLUGIN_RESUME", "JENKINS_RESUME", "INPUT_RESUME"},
+		},
+		&cli.StringFlag{
+			Name:    "summary-file",
+			Usage:   "JSON file recording the queue ID, build, result and duration of every job after the run",
+			EnvVars: []string{"PLUGIN_SUMMARY_FILE", "JENKINS_SUMMARY_FILE", "INPUT_SUMMARY_FILE"},
+		},
+		&cli.StringFlag{
+			Name:    "output-file",
+			Usage:   "dotenv file of the job results (default: jenkins.env on GitLab and Woodpecker)",
+			EnvVars: []string{"PLUGIN_OUTPUT_FILE", "JENKINS_OUTPUT_FILE", "INPUT_OUTPUT_FILE"},
+		},
+		&cli.IntFlag{
+			Name:    "retry-attempts",
+			Usage:   "total attempts for requests failing with transient errors (1 disables retries)",
+			Value:   3,
+			EnvVars: []string{"PLUGIN_RETRY_ATTEMPTS", "JENKINS_RETRY_ATTEMPTS", "INPUT_RETRY_ATTEMPTS"},
+		},
+		&cli.DurationFlag{
+			Name:    "retry-backoff",
+			Usage:   "initial delay between retries, doubled after every attempt",
+			Value:   time.Second,
+			EnvVars: []string{"PLUGIN_RETRY_BACKOFF", "JENKINS_RETRY_BACKOFF", "INPUT_RETRY_BACKOFF"},
+		},
+		&cli.DurationFlag{
+			Name:  "retry-max-backoff",
+			Usage: "maximum delay between retries",
+			Value: 30 * time.Second,
+			EnvVars: []string{
+				"PLUGIN_RETRY_MAX_BACKOFF",
+				"JENKINS_RETRY_MAX_BACKOFF",
+				"INPUT_RETRY_MAX_BACKOFF",
+			},
+		},
+		&cli.IntSliceFlag{
+			Name:  "retry-status-codes",
+			Usage: "HTTP response codes that are retried",
+			Value: cli.NewIntSlice(429, 502, 503, 504),
+			EnvVars: []string{
+				"PLUGIN_RETRY_STATUS_CODES",
+				"JENKINS_RETRY_STATUS_CODES",
+				"INPUT_RETRY_STATUS_CODES",
+			},
+		},
 		&cli.BoolFlag{
 			Name:    "debug",
 			Usage:   "enable debug mode to show detailed parameter information",
 			EnvVars: []string{"PLUGIN_DEBUG", "JENKINS_DEBUG", "INPUT_DEBUG"},
 		},
-	}
-
-	// Override a template
-	cli.AppHelpTemplate = asciiArt + `
-NAME:
-   {{.Name}} - {{.Usage}}
-
-USAGE:
-   {{.HelpName}} {{if .VisibleFlags}}[global options]{{end}}{{if .Commands}} command [command options]{{end}} ` +
-		`{{if .ArgsUsage}}{{.ArgsUsage}}{{else}}[arguments...]{{end}}
-   {{if len .Authors}}
-AUTHOR:
-   {{range .Authors}}{{ . }}{{end}}
-   {{end}}{{if .Commands}}
-COMMANDS:
-{{range .Commands}}{{if not .HideHelp}}   {{join .Names ", "}}{{ "\t"}}{{.Usage}}{{ "\n" }}{{end}}{{end}}{{end}}` +
-		`{{if .VisibleFlags}}
-GLOBAL OPTIONS:
-   {{range .VisibleFlags}}{{.}}
-   {{end}}{{end}}{{if .Copyright }}
-COPYRIGHT:
-   {{.Copyright}}
-   {{end}}{{if .Version}}
-VERSION:
-   {{.Version}}
-   {{end}}
-REPOSITORY:
-    Github: https://github.com/appleboy/drone-jenkins
-`
-
-	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+		&cli.StringFlag{
+			Name:    "log-format",
+			Usage:   "log output format: text or json",
+			Value:   logFormatText,
+			EnvVars: []string{"PLUGIN_LOG_FORMAT", "JENKINS_LOG_FORMAT", "INPUT_LOG_FORMAT"},
+		},
 	}
 }
 
 func run(c *cli.Context) error {
-	// Validate required parameters
-	if c.String("host") == "" {
+	// Validate required parameters, the host and jobs may come from the state file when resuming
+	resume := c.Bool("resume")
+	if c.String("host") == "" && !resume {
 		return fmt.Errorf("host is required")
 	}
 
-	if len(c.StringSlice("job")) == 0 {
+	if len(c.StringSlice("job")) == 0 && c.String("jobs") == "" && !resume {
 		return fmt.Errorf("at least one job is required")
 	}
 
-	// Validate authentication: either (user + token) or remote-token must be provided
+	// Validate authentication: either (user + token) or remote-token must be provided,
+	// other authentication modes are validated by the plugin
 	hasUserAuth := c.String("user") != "" && c.String(tokenParam) != ""
 	hasRemoteToken := c.String("remote-token") != ""
+	hasOtherAuth := !strings.EqualFold(strings.TrimSpace(c.String("auth-mode")), authBasic)
 
-	if !hasUserAuth && !hasRemoteToken {
+	if !hasUserAuth && !hasRemoteToken && !hasOtherAuth {
 		return fmt.Errorf("authentication required: provide either (user + token) or remote-token")
 	}
 
 	plugin := Plugin{
-		BaseURL:      c.String("host"),
-		Username:     c.String("user"),
-		Token:        c.String(tokenParam),
-		RemoteToken:  c.String("remote-token"),
-		Job:          c.StringSlice("job"),
-		Insecure:     c.Bool("insecure"),
-		CACert:       c.String("ca-cert"),
-		Parameters:   c.String("parameters"),
-		Wait:         c.Bool("wait"),
-		PollInterval: c.Duration("poll-interval"),
-		Timeout:      c.Duration("timeout"),
-		Debug:        c.Bool("debug"),
+		BaseURL:          c.String("host"),
+		Username:         c.String("user"),
+		Token:            c.String(tokenParam),
+		RemoteToken:      c.String("remote-token"),
+		AuthMode:         c.String("auth-mode"),
+		OAuthTokenURL:    c.String("oauth-token-url"),
+		OAuthClientID:    c.String("oauth-client-id"),
+		OAuthSecret:      c.String("oauth-client-secret"),
+		OAuthScopes:      c.StringSlice("oauth-scopes"),
+		Headers:          c.String("headers"),
+		Job:              c.StringSlice("job"),
+		Jobs:             c.String("jobs"),
+		Insecure:         c.Bool("insecure"),
+		CACert:           c.String("ca-cert"),
+		ClientCert:       c.String("client-cert"),
+		ClientKey:        c.String("client-key"),
+		KeyPassphrase:    c.String("client-key-passphrase"),
+		Proxy:            c.String("proxy"),
+		Parameters:       c.String("parameters"),
+		ParametersFile:   c.String("parameters-file"),
+		FileParameters:   c.String("file-parameters"),
+		QueryParameters:  c.Bool("query-parameters"),
+		ValidateParams:   c.String("validate-parameters"),
+		Wait:             c.Bool("wait"),
+		PollInterval:     c.Duration("poll-interval"),
+		Timeout:          c.Duration("timeout"),
+		DependsOn:        c.String("depends-on"),
+		Parallel:         c.Bool("parallel"),
+		MaxParallel:      c.Int("max-parallel"),
+		FollowLog:        c.Bool("follow-log"),
+		StageProgress:    c.Bool("stage-progress"),
+		AbortOnCancel:    c.Bool("abort-on-cancel"),
+		AcceptResults:    c.StringSlice("accept-results"),
+		JobAcceptResults: c.String("job-accept-results"),
+		TestReport:       c.Bool("test-report"),
+		Artifacts:        c.StringSlice("artifacts"),
+		ArtifactsDir:     c.String("artifacts-dir"),
+		VerifyArtifacts:  c.Bool("verify-artifacts"),
+		RetryAttempts:    c.Int("retry-attempts"),
+		RetryBackoff:     c.Duration("retry-backoff"),
+		RetryMaxBackoff:  c.Duration("retry-max-backoff"),
+		RetryStatusCodes: c.IntSlice("retry-status-codes"),
+		StateFile:        c.String("state-file"),
+		Resume:           resume,
+		SummaryFile:      c.String("summary-file"),
+		OutputFile:       c.String("output-file"),
+		Debug:            c.Bool("debug"),
 	}
 
 	// Display plugin configuration in debug mode
 	if plugin.Debug {
-		log.Println("=== Debug Mode: Plugin Configuration ===")
-
 		// Create a display copy with masked sensitive data
 		displayPlugin := struct {
-			BaseURL      string
-			Username     string
-			Token        string
-			RemoteToken  string
-			Job          []string
-			Insecure     bool
-			CACert       string
-			Parameters   string
-			Wait         bool
-			PollInterval time.Duration
-			Timeout      time.Duration
-			Debug        bool
+			BaseURL          string
+			Username         string
+			Token            string
+			RemoteToken      string
+			AuthMode         string
+			OAuthTokenURL    string
+			OAuthClientID    string
+			OAuthSecret      string
+			OAuthScopes      []string
+			Headers          string
+			Job              []string
+			Jobs             string
+			Insecure         bool
+			CACert           string
+			ClientCert       string
+			ClientKey        string
+			KeyPassphrase    string
+			Proxy            string
+			Parameters       string
+			ParametersFile   string
+			FileParameters   string
+			QueryParameters  bool
+			ValidateParams   string
+			Wait             bool
+			PollInterval     time.Duration
+			Timeout          time.Duration
+			DependsOn        string
+			Parallel         bool
+			MaxParallel      int
+			FollowLog        bool
+			StageProgress    bool
+			AbortOnCancel    bool
+			AcceptResults    []string
+			JobAcceptResults string
+			TestReport       bool
+			Artifacts        []string
+			ArtifactsDir     string
+			VerifyArtifacts  bool
+			RetryAttempts    int
+			RetryBackoff     time.Duration
+			RetryMaxBackoff  time.Duration
+			RetryStatusCodes []int
+			StateFile        string
+			Resume           bool
+			SummaryFile      string
+			OutputFile       string
+			Debug            bool
 		}{
-			BaseURL:      plugin.BaseURL,
-			Username:     plugin.Username,
-			Token:        maskToken(plugin.Token),
-			RemoteToken:  maskToken(plugin.RemoteToken),
-			Job:          plugin.Job,
-			Insecure:     plugin.Insecure,
-			CACert:       plugin.CACert,
-			Parameters:   plugin.Parameters,
-			Wait:         plugin.Wait,
-			PollInterval: plugin.PollInterval,
-			Timeout:      plugin.Timeout,
-			Debug:        plugin.Debug,
+			BaseURL:          plugin.BaseURL,
+			Username:         plugin.Username,
+			Token:            maskToken(plugin.Token),
+			RemoteToken:      maskToken(plugin.RemoteToken),
+			AuthMode:         plugin.AuthMode,
+			OAuthTokenURL:    plugin.OAuthTokenURL,
+			OAuthClientID:    plugin.OAuthClientID,
+			OAuthSecret:      maskToken(plugin.OAuthSecret),
+			OAuthScopes:      plugin.OAuthScopes,
+			Headers:          maskToken(plugin.Headers),
+			Job:              plugin.Job,
+			Jobs:             plugin.Jobs,
+			Insecure:         plugin.Insecure,
+			CACert:           plugin.CACert,
+			ClientCert:       plugin.ClientCert,
+			ClientKey:        maskToken(plugin.ClientKey),
+			KeyPassphrase:    maskToken(plugin.KeyPassphrase),
+			Proxy:            redactURL(plugin.Proxy),
+			Parameters:       plugin.Parameters,
+			ParametersFile:   plugin.ParametersFile,
+			FileParameters:   plugin.FileParameters,
+			QueryParameters:  plugin.QueryParameters,
+			ValidateParams:   plugin.ValidateParams,
+			Wait:             plugin.Wait,
+			PollInterval:     plugin.PollInterval,
+			Timeout:          plugin.Timeout,
+			DependsOn:        plugin.DependsOn,
+			Parallel:         plugin.Parallel,
+			MaxParallel:      plugin.MaxParallel,
+			FollowLog:        plugin.FollowLog,
+			StageProgress:    plugin.StageProgress,
+			AbortOnCancel:    plugin.AbortOnCancel,
+			AcceptResults:    plugin.AcceptResults,
+			JobAcceptResults: plugin.JobAcceptResults,
+			TestReport:       plugin.TestReport,
+			Artifacts:        plugin.Artifacts,
+			ArtifactsDir:     plugin.ArtifactsDir,
+			VerifyArtifacts:  plugin.VerifyArtifacts,
+			RetryAttempts:    plugin.RetryAttempts,
+			RetryBackoff:     plugin.RetryBackoff,
+			RetryMaxBackoff:  plugin.RetryMaxBackoff,
+			RetryStatusCodes: plugin.RetryStatusCodes,
+			StateFile:        plugin.StateFile,
+			Resume:           plugin.Resume,
+			SummaryFile:      plugin.SummaryFile,
+			OutputFile:       plugin.OutputFile,
+			Debug:            plugin.Debug,
 		}
 
-		if err := godump.Dump(displayPlugin); err != nil {
-			log.Printf("warning: failed to dump plugin configuration: %v", err)
-		}
-		log.Println("========================================")
+		slog.Debug("plugin configuration", "config", displayPlugin)
 	}
 
 	return plugin.Exec(c.Context)
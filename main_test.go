@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+// runTestApp runs the plugin with the global flags and the given arguments
+func runTestApp(args ...string) error {
+	app := &cli.App{Name: "drone-jenkins", Flags: flags(), Action: run}
+	return app.Run(append([]string{"drone-jenkins"}, args...))
+}
+
+// TestRunParallelFlags tests the parallel mode settings reach the plugin
+func TestRunParallelFlags(t *testing.T) {
+	jobs := map[string]string{"job1": "SUCCESS", "job2": "SUCCESS", "job3": "SUCCESS"}
+
+	t.Run("parallel flags", func(t *testing.T) {
+		tj := newTestJenkins(t, jobs, 3)
+
+		err := runTestApp(
+			"--host", tj.URL,
+			"--user", testUserFoo,
+			"--token", testUserBar,
+			"--job", "job1,job2,job3",
+			"--wait",
+			"--poll-interval", "10ms",
+			"--parallel",
+			"--max-parallel", "2",
+		)
+
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"job1", "job2", "job3"}, tj.triggeredJobs())
+		assert.Equal(t, 2, tj.maxRunning)
+	})
+
+	t.Run("parallel environment variables", func(t *testing.T) {
+		tj := newTestJenkins(t, jobs, 3)
+		t.Setenv("PLUGIN_PARALLEL", "true")
+		t.Setenv("PLUGIN_MAX_PARALLEL", "2")
+
+		err := runTestApp(
+			"--host", tj.URL,
+			"--user", testUserFoo,
+			"--token", testUserBar,
+			"--job", "job1,job2,job3",
+			"--wait",
+			"--poll-interval", "10ms",
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, tj.maxRunning)
+	})
+
+	t.Run("sequential by default", func(t *testing.T) {
+		tj := newTestJenkins(t, jobs, 3)
+
+		err := runTestApp(
+			"--host", tj.URL,
+			"--user", testUserFoo,
+			"--token", testUserBar,
+			"--job", "job1,job2,job3",
+			"--wait",
+			"--poll-interval", "10ms",
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, tj.maxRunning)
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// loadParametersFile reads build parameters from a JSON object, a YAML mapping
+// or a dotenv file, chosen by the file extension (.json, .yaml, .yml, anything else is dotenv).
+// In JSON and YAML files a list value sends the parameter once per item.
+func loadParametersFile(path string) (url.Values, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parameters file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("failed to parse parameters file %s: %w", path, err)
+		}
+		if len(node.Content) == 0 {
+			return url.Values{}, nil
+		}
+		if node.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("parameters file %s must contain an object", path)
+		}
+
+		var params jobParameters
+		if err := node.Content[0].Decode(&params); err != nil {
+			return nil, fmt.Errorf("failed to parse parameters file %s: %w", path, err)
+		}
+		return url.Values(params), nil
+	default:
+		env, err := godotenv.Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse parameters file %s: %w", path, err)
+		}
+
+		params := url.Values{}
+		for key, value := range env {
+			params.Set(key, value)
+		}
+		return params, nil
+	}
+}
+
+// parseParameterSetting parses the parameters setting, given either as key=value lines
+// or as a JSON object, the form Drone and Woodpecker pass map settings in
+func parseParameterSetting(input string) (url.Values, error) {
+	if !strings.HasPrefix(strings.TrimSpace(input), "{") {
+		return parseParameters(input), nil
+	}
+
+	var params jobParameters
+	if err := yaml.Unmarshal([]byte(input), &params); err != nil {
+		return nil, fmt.Errorf("invalid parameters object: %w", err)
+	}
+
+	return url.Values(params), nil
+}
+
+// mergeParameters returns base with every key of override replacing the base values
+func mergeParameters(base, override url.Values) url.Values {
+	merged := cloneValues(base)
+	for key, values := range override {
+		merged[key] = values
+	}
+
+	return merged
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestFile writes content to a file in a temporary directory and returns its path
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadParametersFile(t *testing.T) {
+	expected := url.Values{
+		"VERSION":  {"1.2.3"},
+		"REPLICAS": {"3"},
+		"TARGETS":  {"eu", "us"},
+		"NOTES":    {"line one\nline two"},
+		"EMPTY":    {""},
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected url.Values
+	}{
+		{
+			name: "json",
+			file: "params.json",
+			content: `{"VERSION": "1.2.3", "REPLICAS": 3, "TARGETS": ["eu", "us"],` +
+				`"NOTES": "line one\nline two", "EMPTY": null}`,
+			expected: expected,
+		},
+		{
+			name: "yaml",
+			file: "params.yml",
+			content: `VERSION: 1.2.3
+REPLICAS: 3
+TARGETS:
+  - eu
+  - us
+NOTES: |-
+  line one
+  line two
+EMPTY:
+`,
+			expected: expected,
+		},
+		{
+			name: "dotenv",
+			file: "params.env",
+			content: `# deployment settings
+VERSION=1.2.3
+NOTES="line one\nline two"
+export REPLICAS=3
+`,
+			expected: url.Values{
+				"VERSION":  {"1.2.3"},
+				"REPLICAS": {"3"},
+				"NOTES":    {"line one\nline two"},
+			},
+		},
+		{
+			name:     "empty yaml",
+			file:     "params.yaml",
+			content:  "",
+			expected: url.Values{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := loadParametersFile(writeTestFile(t, tt.file, tt.content))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, params)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := loadParametersFile(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read parameters file")
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		_, err := loadParametersFile(writeTestFile(t, "params.json", `["VERSION"]`))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must contain an object")
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := loadParametersFile(writeTestFile(t, "params.json", `{"VERSION": `))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse parameters file")
+	})
+}
+
+func TestParseParameterSetting(t *testing.T) {
+	params, err := parseParameterSetting("VERSION=1.2.3\nENV=prod")
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"VERSION": {"1.2.3"}, "ENV": {"prod"}}, params)
+
+	// Map settings are passed by Drone and Woodpecker as a JSON object
+	params, err = parseParameterSetting(` {"VERSION":"1.2.3","REPLICAS":3,"TARGETS":["eu","us"]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"VERSION":  {"1.2.3"},
+		"REPLICAS": {"3"},
+		"TARGETS":  {"eu", "us"},
+	}, params)
+
+	_, err = parseParameterSetting(`{"VERSION":`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid parameters object")
+}
+
+func TestMergeParameters(t *testing.T) {
+	base := url.Values{"A": {"1"}, "B": {"2", "3"}}
+	merged := mergeParameters(base, url.Values{"B": {"4"}, "C": {"5"}})
+
+	assert.Equal(t, url.Values{"A": {"1"}, "B": {"4"}, "C": {"5"}}, merged)
+	assert.Equal(t, []string{"2", "3"}, base["B"])
+}
+
+// TestExecWithParametersFile tests that inline parameters override the parameters file
+func TestExecWithParametersFile(t *testing.T) {
+	var receivedForm url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		receivedForm = r.PostForm
+		w.Header().Set("Location", "http://jenkins.example.com/queue/item/1/")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	plugin := Plugin{
+		BaseURL:        server.URL,
+		Username:       testUserFoo,
+		Token:          testUserBar,
+		Job:            []string{"parameterized-job"},
+		Parameters:     "environment=staging",
+		ParametersFile: writeTestFile(t, "params.yaml", "environment: production\nregions: [eu, us]\n"),
+	}
+
+	err := plugin.Exec(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", receivedForm.Get("environment"))
+	assert.Equal(t, []string{"eu", "us"}, receivedForm["regions"])
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,18 +15,75 @@ type (
 	// Plugin represents the configuration for the Jenkins plugin.
 	// It contains all necessary credentials and settings to trigger Jenkins jobs.
 	Plugin struct {
-		BaseURL      string        // Jenkins server base URL
-		Username     string        // Jenkins username for authentication
-		Token        string        // Jenkins API token for authentication
-		RemoteToken  string        // Optional remote trigger token for additional security
-		Job          []string      // List of Jenkins job names to trigger
-		Insecure     bool          // Whether to skip TLS certificate verification
-		CACert       string        // Custom CA certificate (PEM content, file path, or HTTP URL)
-		Parameters   string        // Job parameters in key=value format (one per line)
-		Wait         bool          // Whether to wait for job completion
-		PollInterval time.Duration // Interval between status checks (default: 10s)
-		Timeout      time.Duration // Maximum time to wait for job completion (default: 30m)
-		Debug        bool          // Enable debug mode to show detailed parameter information
+		BaseURL          string        // Jenkins server base URL
+		Username         string        // Jenkins username for authentication
+		Token            string        // Jenkins API token for authentication
+		RemoteToken      string        // Optional remote trigger token for additional security
+		AuthMode         string        // Authentication mode: basic, bearer (Token as bearer token) or oauth2
+		OAuthTokenURL    string        // OAuth2 token endpoint for the client credentials grant
+		OAuthClientID    string        // OAuth2 client ID
+		OAuthSecret      string        // OAuth2 client secret
+		OAuthScopes      []string      // OAuth2 scopes to request
+		Headers          string        // Extra request headers in Name: value format (one per line)
+		Job              []string      // List of Jenkins job names to trigger
+		Jobs             string        // Structured JSON or YAML list of jobs with their own settings
+		Insecure         bool          // Whether to skip TLS certificate verification
+		CACert           string        // Custom CA certificate (PEM content, file path, or HTTP URL)
+		ClientCert       string        // Client certificate for mutual TLS (PEM content, file path, or HTTP URL)
+		ClientKey        string        // Private key of the client certificate (PEM content, file path, or HTTP URL)
+		KeyPassphrase    string        // Passphrase of an encrypted client key
+		Proxy            string        // http, https or socks5 proxy URL (default: proxy environment variables)
+		Parameters       string        // Job parameters in key=value format (one per line)
+		ParametersFile   string        // JSON, YAML or dotenv file of job parameters
+		FileParameters   string        // File parameters in name=path format (one per line)
+		QueryParameters  bool          // Send parameters in the query string instead of the request body
+		ValidateParams   string        // Check parameters against the job definitions: off, warn or fail
+		Wait             bool          // Whether to wait for job completion
+		PollInterval     time.Duration // Interval between status checks (default: 10s)
+		Timeout          time.Duration // Maximum time to wait for job completion (default: 30m)
+		DependsOn        string        // Job dependencies in job=dep1,dep2 format (one per line)
+		Parallel         bool          // Trigger and wait for all jobs concurrently
+		MaxParallel      int           // Maximum number of concurrent jobs in parallel mode (0: unlimited)
+		FollowLog        bool          // Stream the build console log while waiting for completion
+		StageProgress    bool          // Log Pipeline stage transitions while waiting for completion
+		AbortOnCancel    bool          // Abort the Jenkins build when waiting is cancelled or times out
+		AcceptResults    []string      // Build results treated as success (default: SUCCESS)
+		JobAcceptResults string        // Per-job accepted results in job=RESULT1,RESULT2 format
+		TestReport       bool          // Fetch and summarise the JUnit test report of finished builds
+		Artifacts        []string      // Glob patterns of build artifacts to download after success
+		ArtifactsDir     string        // Directory to download artifacts into (default: current directory)
+		VerifyArtifacts  bool          // Verify downloaded artifacts against Jenkins MD5 fingerprints
+		RetryAttempts    int           // Total attempts for transient request failures (default: 3)
+		RetryBackoff     time.Duration // Initial delay between retries (default: 1s)
+		RetryMaxBackoff  time.Duration // Maximum delay between retries (default: 30s)
+		RetryStatusCodes []int         // Retried response codes (default: 429, 502, 503, 504)
+		StateFile        string        // JSON file recording the triggered jobs for a later resume
+		Resume           bool          // Wait for the jobs recorded in StateFile instead of triggering
+		SummaryFile      string        // JSON file recording the outcome of every job after the run
+		OutputFile       string        // Dotenv file of the job results (default: jenkins.env on GitLab and Woodpecker)
+		Debug            bool          // Enable debug mode to show detailed parameter information
+	}
+
+	// runner holds the settings shared by every job triggered by a single Exec call
+	runner struct {
+		jenkins      *Jenkins
+		params       url.Values
+		files        map[string]string // Files uploaded as file parameters, by parameter name
+		validate     string            // Parameter validation mode
+		wait         bool
+		pollInterval time.Duration
+		timeout      time.Duration
+		maxParallel  int
+		policy       *resultPolicy
+		testReport   bool
+		artifacts    []string
+		artifactsDir string
+		verify       bool
+		jobs         map[string]*JobConfig // Per-job settings from the structured jobs setting
+		forceWait    bool                  // Wait for every job regardless of its settings
+		state        *stateRecorder        // Records triggered jobs, nil without a state file
+		resumed      map[string]JobState   // Jobs to wait for without triggering, by job name
+		summary      *summaryRecorder      // Records the outcome of every job
 	}
 )
 
@@ -61,10 +119,7 @@ func parseParameters(params string) url.Values {
 
 		parts := strings.SplitN(trimmedLine, "=", 2)
 		if len(parts) != 2 {
-			log.Printf(
-				"warning: skipping invalid parameter format (expected key=value): %q",
-				trimmedLine,
-			)
+			slog.Warn("skipping invalid parameter, expected key=value", "line", trimmedLine)
 			continue
 		}
 
@@ -72,7 +127,7 @@ func parseParameters(params string) url.Values {
 		value := parts[1] // Keep value as-is to preserve intentional spaces
 
 		if key == "" {
-			log.Printf("warning: skipping parameter with empty key: %q", trimmedLine)
+			slog.Warn("skipping parameter with empty key", "line", trimmedLine)
 			continue
 		}
 
@@ -89,50 +144,198 @@ func (p Plugin) validateConfig() error {
 		return errors.New("jenkins base URL is required")
 	}
 
-	// Validate authentication: either (user + token) or remote-token must be provided
-	hasUserAuth := p.Username != "" && p.Token != ""
-	hasRemoteToken := p.RemoteToken != ""
+	mode, err := parseAuthMode(p.AuthMode)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case authBearer:
+		if p.Token == "" {
+			return errors.New("authentication required: bearer mode needs a token")
+		}
+	case authOAuth2:
+		if p.OAuthTokenURL == "" || p.OAuthClientID == "" || p.OAuthSecret == "" {
+			return errors.New("authentication required: oauth2 mode needs a token URL, client ID and secret")
+		}
+	default:
+		// Validate authentication: either (user + token) or remote-token must be provided
+		hasUserAuth := p.Username != "" && p.Token != ""
+		hasRemoteToken := p.RemoteToken != ""
 
-	if !hasUserAuth && !hasRemoteToken {
-		return errors.New("authentication required")
+		if !hasUserAuth && !hasRemoteToken {
+			return errors.New("authentication required")
+		}
 	}
 
 	return nil
 }
 
+// retryPolicy returns the configured retry policy, using defaults for unset values
+func (p Plugin) retryPolicy() RetryPolicy {
+	policy := defaultRetryPolicy()
+	if p.RetryAttempts > 0 {
+		policy.MaxAttempts = p.RetryAttempts
+	}
+	if p.RetryBackoff > 0 {
+		policy.Backoff = p.RetryBackoff
+	}
+	if p.RetryMaxBackoff > 0 {
+		policy.MaxBackoff = p.RetryMaxBackoff
+	}
+	if len(p.RetryStatusCodes) > 0 {
+		policy.StatusCodes = p.RetryStatusCodes
+	}
+
+	return policy
+}
+
+// newJenkins creates a Jenkins client from the connection, authentication and retry settings
+func (p Plugin) newJenkins(ctx context.Context) (*Jenkins, error) {
+	mode, err := parseAuthMode(p.AuthMode)
+	if err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+	headers, err := parseHeaders(p.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+
+	// Set up basic authentication (only if username and token are provided)
+	var auth *Auth
+	if mode == authBasic && p.Username != "" && p.Token != "" {
+		auth = &Auth{
+			Username: p.Username,
+			Token:    p.Token,
+		}
+	}
+
+	jenkins, err := NewJenkins(
+		ctx,
+		auth,
+		p.BaseURL,
+		p.RemoteToken,
+		p.Insecure,
+		p.CACert,
+		p.ClientCert,
+		p.ClientKey,
+		p.KeyPassphrase,
+		p.Proxy,
+		p.Debug,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Jenkins client: %w", err)
+	}
+	jenkins.Retry = p.retryPolicy()
+	jenkins.Headers = headers
+
+	switch mode {
+	case authBearer:
+		if p.Token != "" {
+			jenkins.Authenticator = &bearerAuth{token: p.Token}
+		}
+	case authOAuth2:
+		// Tokens are requested through the same TLS and proxy settings as Jenkins
+		jenkins.Authenticator = newOAuth2Auth(
+			jenkins.Client,
+			p.OAuthTokenURL,
+			p.OAuthClientID,
+			p.OAuthSecret,
+			trimWhitespaceFromSlice(p.OAuthScopes),
+		)
+	}
+
+	return jenkins, nil
+}
+
 // Exec executes the plugin by triggering the configured Jenkins jobs.
 // It validates the configuration, parses parameters, and triggers each job sequentially.
 // Returns an error if validation fails or any job trigger fails.
 // The context can be used to cancel operations mid-execution.
 func (p Plugin) Exec(ctx context.Context) error {
+	// Combine the job list with the structured jobs setting
+	configs, err := parseJobConfigs(p.Jobs)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	jobs, jobConfigs, err := mergeJobs(trimWhitespaceFromSlice(p.Job), configs)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	// Load the jobs triggered by a previous step
+	var resumed map[string]JobState
+	if p.Resume {
+		if p.StateFile == "" {
+			return errors.New("configuration error: state file is required to resume")
+		}
+
+		state, err := readState(p.StateFile)
+		if err != nil {
+			return err
+		}
+
+		var baseURL string
+		if jobs, resumed, baseURL, err = state.resumeJobs(jobs); err != nil {
+			return err
+		}
+		if p.BaseURL == "" {
+			p.BaseURL = baseURL
+		} else if strings.TrimRight(p.BaseURL, "/") != baseURL {
+			return fmt.Errorf("state file was written for %s, not %s", baseURL, p.BaseURL)
+		}
+		p.Wait = true
+	}
+
 	// Validate required configuration
 	if err := p.validateConfig(); err != nil {
 		return fmt.Errorf("configuration error: %w", err)
 	}
 
-	// Clean and validate job list
-	jobs := trimWhitespaceFromSlice(p.Job)
+	// Validate job list
 	if len(jobs) == 0 {
 		return errors.New("at least one Jenkins job name is required")
 	}
 
-	// Set up authentication (only if username and token are provided)
-	var auth *Auth
-	if p.Username != "" && p.Token != "" {
-		auth = &Auth{
-			Username: p.Username,
-			Token:    p.Token,
+	// Initialize Jenkins client
+	jenkins, err := p.newJenkins(ctx)
+	if err != nil {
+		return err
+	}
+	jenkins.FollowLog = p.FollowLog
+	jenkins.StageProgress = p.StageProgress
+	jenkins.LogPrefix = len(jobs) > 1
+	jenkins.AbortOnCancel = p.AbortOnCancel
+	jenkins.QueryParams = p.QueryParameters
+
+	// Parse the accepted build results
+	policy, err := newResultPolicy(p.AcceptResults, p.JobAcceptResults)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+
+	// Inline parameters take precedence over the parameters file
+	params, err := parseParameterSetting(p.Parameters)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if p.ParametersFile != "" {
+		fileParams, err := loadParametersFile(p.ParametersFile)
+		if err != nil {
+			return fmt.Errorf("configuration error: %w", err)
 		}
+		params = mergeParameters(fileParams, params)
 	}
 
-	// Initialize Jenkins client
-	jenkins, err := NewJenkins(ctx, auth, p.BaseURL, p.RemoteToken, p.Insecure, p.CACert, p.Debug)
+	files, err := parseFileParameters(p.FileParameters)
 	if err != nil {
-		return fmt.Errorf("failed to initialize Jenkins client: %w", err)
+		return fmt.Errorf("configuration error: %w", err)
 	}
 
-	// Parse job parameters
-	params := parseParameters(p.Parameters)
+	validate, err := parseValidateMode(p.ValidateParams)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
 
 	// Set default values for wait configuration
 	pollInterval := p.PollInterval
@@ -145,40 +348,263 @@ func (p Plugin) Exec(ctx context.Context) error {
 		timeout = 30 * time.Minute
 	}
 
+	r := &runner{
+		jenkins:      jenkins,
+		params:       params,
+		files:        files,
+		validate:     validate,
+		wait:         p.Wait,
+		pollInterval: pollInterval,
+		timeout:      timeout,
+		maxParallel:  p.MaxParallel,
+		policy:       policy,
+		testReport:   p.TestReport,
+		artifacts:    trimWhitespaceFromSlice(p.Artifacts),
+		artifactsDir: p.ArtifactsDir,
+		verify:       p.VerifyArtifacts,
+		jobs:         jobConfigs,
+		forceWait:    p.Resume,
+		resumed:      resumed,
+		summary:      newSummaryRecorder(jobs),
+	}
+	if !p.Resume {
+		r.state = newStateRecorder(p.StateFile, jenkins.BaseURL)
+	}
+
+	err = r.run(ctx, jobs, parseJobLists(p.DependsOn), p.Parallel)
+
+	// Outcomes are reported whether or not the jobs succeeded
+	summary := r.summary.summary()
+	publishOutputs(summary, p.OutputFile)
+	if p.SummaryFile != "" {
+		if summaryErr := writeSummaryFile(p.SummaryFile, summary); summaryErr != nil {
+			return errors.Join(err, summaryErr)
+		}
+	}
+
+	return err
+}
+
+// run runs the jobs as a dependency graph, concurrently or one after the other
+func (r *runner) run(ctx context.Context, jobs []string, deps map[string][]string, parallel bool) error {
+	// Run jobs in dependency order when a job graph is configured
+	if len(deps) > 0 {
+		return r.runGraph(ctx, jobs, deps)
+	}
+
+	if parallel {
+		return r.runParallel(ctx, jobs)
+	}
+
 	// Trigger each job
 	for _, jobName := range jobs {
-		queueID, err := jenkins.trigger(ctx, jobName, params)
-		if err != nil {
-			return fmt.Errorf("failed to trigger job %q: %w", jobName, err)
-		}
-		log.Printf("successfully triggered job: %s (queue #%d)", jobName, queueID)
-
-		// Wait for job completion if requested
-		if p.Wait {
-			buildInfo, err := jenkins.waitForCompletion(
-				ctx,
-				jobName,
-				queueID,
-				pollInterval,
-				timeout,
+		if _, err := r.runJob(ctx, jobName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runParallel triggers and waits for all jobs concurrently, running at most
+// maxParallel jobs at a time (unlimited when zero).
+// Every failure is collected and reported together once all jobs have finished.
+func (r *runner) runParallel(ctx context.Context, jobs []string) error {
+	limit := r.maxParallel
+	if limit <= 0 || limit > len(jobs) {
+		limit = len(jobs)
+	}
+
+	builds := make([]*BuildInfo, len(jobs))
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	for i, jobName := range jobs {
+		// Acquire a slot before starting the goroutine, so jobs start in the configured order
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			builds[i], errs[i] = r.runJob(ctx, jobName)
+		}()
+	}
+	wg.Wait()
+
+	// Report the aggregated results
+	failed := 0
+	for i, jobName := range jobs {
+		switch {
+		case errs[i] != nil:
+			failed++
+			slog.Error("job failed", "job", jobName, "error", errs[i])
+		case builds[i] != nil:
+			slog.Info(
+				"job finished",
+				"job", jobName,
+				"build_number", builds[i].Number,
+				"result", builds[i].Result,
 			)
-			if err != nil {
-				return fmt.Errorf("error waiting for job %q: %w", jobName, err)
-			}
-
-			// Check if build was successful
-			if buildInfo.Result != "SUCCESS" {
-				return fmt.Errorf(
-					"job %q (build #%d) failed with status: %s",
-					jobName,
-					buildInfo.Number,
-					buildInfo.Result,
-				)
-			}
-
-			log.Printf("job %s (build #%d) completed successfully", jobName, buildInfo.Number)
 		}
 	}
 
+	if failed > 0 {
+		return fmt.Errorf("%d of %d jobs failed: %w", failed, len(jobs), errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// runJob triggers a single job and, if waiting is enabled, waits for it to complete.
+// Jobs resumed from a state file are not triggered again.
+// The returned BuildInfo is nil when not waiting.
+func (r *runner) runJob(ctx context.Context, jobName string) (build *BuildInfo, err error) {
+	var (
+		queueID int
+		report  *TestReport
+	)
+	defer func() {
+		r.summary.record(jobName, queueID, build, report, err)
+	}()
+
+	queueID, err = r.triggerJob(ctx, jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.jobWait(jobName) {
+		return nil, nil
+	}
+
+	buildInfo, err := r.jenkins.waitForCompletion(
+		ctx,
+		jobName,
+		queueID,
+		r.pollInterval,
+		r.jobTimeout(jobName),
+	)
+	if err != nil {
+		// A build that started before timing out or being cancelled keeps its number and URL in the summary
+		return buildInfo, fmt.Errorf("error waiting for job %q: %w", jobName, err)
+	}
+
+	if r.testReport {
+		report = r.jenkins.reportTests(ctx, jobName, buildInfo.Number)
+	}
+
+	// Check if the build result is accepted
+	if !r.policy.accepts(jobName, buildInfo.Result) {
+		return buildInfo, &ResultError{
+			Job:    jobName,
+			Number: buildInfo.Number,
+			Result: buildInfo.Result,
+		}
+	}
+
+	if buildInfo.Result != resultSuccess {
+		slog.Warn(
+			"job completed with accepted status",
+			"job", jobName,
+			"build_number", buildInfo.Number,
+			"result", buildInfo.Result,
+		)
+	} else {
+		slog.Info(
+			"job completed successfully",
+			"job", jobName,
+			"build_number", buildInfo.Number,
+			"result", buildInfo.Result,
+		)
+	}
+
+	if err := r.downloadArtifacts(ctx, jobName, buildInfo); err != nil {
+		return buildInfo, err
+	}
+
+	return buildInfo, nil
+}
+
+// jobParams returns the build parameters of a job, the shared parameters
+// overridden by the job's own parameters
+func (r *runner) jobParams(jobName string) url.Values {
+	cfg, ok := r.jobs[jobName]
+	if !ok || len(cfg.Parameters) == 0 {
+		return r.params
+	}
+
+	return mergeParameters(r.params, url.Values(cfg.Parameters))
+}
+
+// jobWait reports whether to wait for a job to complete
+func (r *runner) jobWait(jobName string) bool {
+	if r.forceWait {
+		return true
+	}
+	if cfg, ok := r.jobs[jobName]; ok && cfg.Wait != nil {
+		return *cfg.Wait
+	}
+
+	return r.wait
+}
+
+// jobTimeout returns the maximum time to wait for a job
+func (r *runner) jobTimeout(jobName string) time.Duration {
+	if cfg, ok := r.jobs[jobName]; ok && cfg.Timeout > 0 {
+		return time.Duration(cfg.Timeout)
+	}
+
+	return r.timeout
+}
+
+// triggerJob triggers a job and records it in the state file, or returns the
+// queue ID recorded for a resumed job
+func (r *runner) triggerJob(ctx context.Context, jobName string) (int, error) {
+	if entry, ok := r.resumed[jobName]; ok {
+		slog.Info("resuming job", "job", jobName, "queue_id", entry.QueueID)
+		return entry.QueueID, nil
+	}
+
+	params := r.jobParams(jobName)
+	if r.validate != validateOff {
+		// File parameters are checked along with the others
+		checked := cloneValues(params)
+		for name, path := range r.files {
+			checked.Set(name, path)
+		}
+		if err := r.jenkins.checkParameters(ctx, jobName, checked, r.validate); err != nil {
+			return 0, err
+		}
+	}
+
+	queueID, err := r.jenkins.trigger(ctx, jobName, params, r.files)
+	if err != nil {
+		return 0, fmt.Errorf("failed to trigger job %q: %w", jobName, err)
+	}
+	slog.Info("triggered job", "job", jobName, "queue_id", queueID)
+
+	if err := r.state.record(jobName, queueID); err != nil {
+		return queueID, err
+	}
+
+	return queueID, nil
+}
+
+// downloadArtifacts downloads the configured artifacts of a finished build
+func (r *runner) downloadArtifacts(ctx context.Context, jobName string, build *BuildInfo) error {
+	if len(r.artifacts) == 0 {
+		return nil
+	}
+
+	dir := r.artifactsDir
+	if dir == "" {
+		dir = "."
+	}
+
+	if err := r.jenkins.downloadArtifacts(ctx, jobName, build, r.artifacts, dir, r.verify); err != nil {
+		return fmt.Errorf("failed to download artifacts of job %q: %w", jobName, err)
+	}
+
 	return nil
 }
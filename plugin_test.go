@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -367,9 +370,10 @@ func TestExecTriggerMultipleJobs(t *testing.T) {
 // TestExecWithParameters tests job triggering with parameters
 func TestExecWithParameters(t *testing.T) {
 	// Create a mock Jenkins server
-	var receivedQuery url.Values
+	var receivedForm url.Values
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		receivedQuery = r.URL.Query()
+		assert.NoError(t, r.ParseForm())
+		receivedForm = r.PostForm
 		w.Header().Set("Location", "http://jenkins.example.com/queue/item/1/")
 		w.WriteHeader(http.StatusCreated)
 	}))
@@ -386,8 +390,8 @@ func TestExecWithParameters(t *testing.T) {
 	err := plugin.Exec(context.Background())
 
 	assert.NoError(t, err)
-	assert.Equal(t, "main", receivedQuery.Get("branch"))
-	assert.Equal(t, "production", receivedQuery.Get("environment"))
+	assert.Equal(t, "main", receivedForm.Get("branch"))
+	assert.Equal(t, "production", receivedForm.Get("environment"))
 }
 
 // TestExecWithRemoteToken tests job triggering with remote token
@@ -395,7 +399,7 @@ func TestExecWithRemoteToken(t *testing.T) {
 	// Create a mock Jenkins server
 	var receivedToken string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		receivedToken = r.URL.Query().Get("token")
+		receivedToken = r.PostFormValue("token")
 		w.Header().Set("Location", "http://jenkins.example.com/queue/item/1/")
 		w.WriteHeader(http.StatusCreated)
 	}))
@@ -512,3 +516,182 @@ func TestExecWithWaitFailure(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed with status: FAILURE")
 }
+
+// testJenkins is a mock Jenkins server that runs jobs with predefined results.
+// Each build reports as building until it has been polled buildPolls times.
+type testJenkins struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	results    map[string]string // Job name to final build result
+	buildPolls int
+	queue      map[int]string // Queue ID to job name
+	polls      map[string]int
+	triggered  []string
+	running    int
+	maxRunning int
+}
+
+func newTestJenkins(t *testing.T, results map[string]string, buildPolls int) *testJenkins {
+	t.Helper()
+
+	tj := &testJenkins{
+		results:    results,
+		buildPolls: buildPolls,
+		queue:      map[int]string{},
+		polls:      map[string]int{},
+	}
+	tj.Server = httptest.NewServer(http.HandlerFunc(tj.handle))
+	t.Cleanup(tj.Close)
+
+	return tj
+}
+
+func (tj *testJenkins) handle(w http.ResponseWriter, r *http.Request) {
+	tj.mu.Lock()
+	defer tj.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "job":
+		// Trigger: /job/{name}/build or /job/{name}/buildWithParameters
+		queueID := len(tj.queue) + 1
+		tj.queue[queueID] = parts[1]
+		tj.triggered = append(tj.triggered, parts[1])
+		tj.running++
+		tj.maxRunning = max(tj.maxRunning, tj.running)
+		w.Header().Set("Location", fmt.Sprintf("%s/queue/item/%d/", tj.URL, queueID))
+		w.WriteHeader(http.StatusCreated)
+	case len(parts) == 5 && parts[0] == "queue":
+		// Queue item: /queue/item/{id}/api/json, build number equals the queue ID
+		_, _ = fmt.Fprintf(w, `{"id":%s,"executable":{"number":%s}}`, parts[2], parts[2])
+	case len(parts) == 5 && parts[0] == "job" && parts[3] == "api":
+		// Build info: /job/{name}/{number}/api/json
+		name := parts[1]
+		tj.polls[name]++
+		if tj.polls[name] < tj.buildPolls {
+			_, _ = fmt.Fprintf(w, `{"number":%s,"building":true,"url":"%s/job/%s/%s/"}`, parts[2], tj.URL, name, parts[2])
+			return
+		}
+		if tj.polls[name] == tj.buildPolls {
+			tj.running--
+		}
+		_, _ = fmt.Fprintf(
+			w,
+			`{"number":%s,"building":false,"result":%q,"url":"%s/job/%s/%s/"}`,
+			parts[2], tj.results[name], tj.URL, name, parts[2],
+		)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// triggeredJobs returns the names of the triggered jobs in trigger order
+func (tj *testJenkins) triggeredJobs() []string {
+	tj.mu.Lock()
+	defer tj.mu.Unlock()
+	return append([]string(nil), tj.triggered...)
+}
+
+// TestExecParallel tests triggering and waiting for jobs concurrently
+func TestExecParallel(t *testing.T) {
+	t.Run("all jobs succeed", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{
+			"job1": "SUCCESS",
+			"job2": "SUCCESS",
+			"job3": "SUCCESS",
+		}, 5)
+
+		plugin := Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"job1", "job2", "job3"},
+			Wait:         true,
+			Parallel:     true,
+			PollInterval: 10 * time.Millisecond,
+		}
+
+		err := plugin.Exec(context.Background())
+
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"job1", "job2", "job3"}, tj.triggeredJobs())
+		assert.Equal(t, 3, tj.maxRunning)
+	})
+
+	t.Run("max parallel limits concurrency", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{
+			"job1": "SUCCESS",
+			"job2": "SUCCESS",
+			"job3": "SUCCESS",
+			"job4": "SUCCESS",
+		}, 3)
+
+		plugin := Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"job1", "job2", "job3", "job4"},
+			Wait:         true,
+			Parallel:     true,
+			MaxParallel:  2,
+			PollInterval: 10 * time.Millisecond,
+		}
+
+		err := plugin.Exec(context.Background())
+
+		assert.NoError(t, err)
+		assert.Len(t, tj.triggeredJobs(), 4)
+		assert.LessOrEqual(t, tj.maxRunning, 2)
+	})
+
+	t.Run("jobs start in configured order", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{
+			"job1": "SUCCESS",
+			"job2": "SUCCESS",
+			"job3": "SUCCESS",
+			"job4": "SUCCESS",
+		}, 1)
+
+		plugin := Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"job4", "job2", "job3", "job1"},
+			Wait:         true,
+			Parallel:     true,
+			MaxParallel:  1,
+			PollInterval: 10 * time.Millisecond,
+		}
+
+		assert.NoError(t, plugin.Exec(context.Background()))
+		assert.Equal(t, []string{"job4", "job2", "job3", "job1"}, tj.triggeredJobs())
+	})
+
+	t.Run("all failures are reported", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{
+			"job1": "FAILURE",
+			"job2": "SUCCESS",
+			"job3": "ABORTED",
+		}, 1)
+
+		plugin := Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"job1", "job2", "job3"},
+			Wait:         true,
+			Parallel:     true,
+			PollInterval: 10 * time.Millisecond,
+		}
+
+		err := plugin.Exec(context.Background())
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "2 of 3 jobs failed")
+		assert.Contains(t, err.Error(), `job "job1" (build #`)
+		assert.Contains(t, err.Error(), "failed with status: FAILURE")
+		assert.Contains(t, err.Error(), "failed with status: ABORTED")
+		assert.Len(t, tj.triggeredJobs(), 3)
+	})
+}
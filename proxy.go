@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// proxyFunc returns the proxy selection of the HTTP transport: the given http, https or
+// socks5 proxy URL, optionally with credentials, or the standard HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY environment variables when empty. Hosts listed in NO_PROXY still bypass
+// the given proxy.
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	if proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	// The parse error is not wrapped as it would reveal the credentials
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.New("invalid proxy URL")
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf(
+			"unsupported proxy scheme %q (expected http, https or socks5)",
+			proxyURL.Scheme,
+		)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %s: missing host", proxyURL.Redacted())
+	}
+
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+
+	// Localhost and loopback addresses are never proxied, as with the environment variables
+	proxyForURL := (&httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    noProxy,
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyForURL(req.URL)
+	}, nil
+}
+
+// redactURL masks the password of a URL for display
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "***MASKED***"
+	}
+
+	return u.Redacted()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// maxReportedFailures limits the number of failing test cases printed per build
+const maxReportedFailures = 50
+
+type (
+	// TestReport represents the JUnit test report of a Jenkins build
+	TestReport struct {
+		FailCount    int         `json:"failCount"`
+		PassCount    int         `json:"passCount"`
+		SkipCount    int         `json:"skipCount"`
+		TotalCount   int         `json:"totalCount"` // Only set for aggregated reports
+		Suites       []TestSuite `json:"suites"`
+		ChildReports []struct {
+			Result TestReport `json:"result"`
+		} `json:"childReports"` // Set for aggregated (e.g. Maven or matrix) reports
+	}
+
+	// TestSuite represents a suite in a Jenkins test report
+	TestSuite struct {
+		Name  string     `json:"name"`
+		Cases []TestCase `json:"cases"`
+	}
+
+	// TestCase represents a single test case in a Jenkins test report
+	TestCase struct {
+		ClassName    string `json:"className"`
+		Name         string `json:"name"`
+		Status       string `json:"status"` // PASSED, SKIPPED, FAILED, FIXED, REGRESSION
+		ErrorDetails string `json:"errorDetails"`
+	}
+)
+
+// Passed returns the number of passed tests, derived from the total for aggregated reports
+func (r *TestReport) Passed() int {
+	if r.PassCount == 0 && r.TotalCount > 0 {
+		return r.TotalCount - r.FailCount - r.SkipCount
+	}
+
+	return r.PassCount
+}
+
+// Total returns the total number of tests
+func (r *TestReport) Total() int {
+	return r.Passed() + r.FailCount + r.SkipCount
+}
+
+// FailedCases returns the failing test cases of the report and its child reports
+func (r *TestReport) FailedCases() []TestCase {
+	var failed []TestCase
+	for _, suite := range r.Suites {
+		for _, c := range suite.Cases {
+			if c.Status == "FAILED" || c.Status == "REGRESSION" {
+				failed = append(failed, c)
+			}
+		}
+	}
+
+	for _, child := range r.ChildReports {
+		failed = append(failed, child.Result.FailedCases()...)
+	}
+
+	return failed
+}
+
+// getTestReport fetches the JUnit test report of a build.
+// It returns nil without error if the build has no test report.
+func (jenkins *Jenkins) getTestReport(
+	ctx context.Context,
+	job string,
+	buildNumber int,
+) (*TestReport, error) {
+	path := fmt.Sprintf("%s/%d/testReport/api/json", jenkins.parseJobPath(job), buildNumber)
+
+	var report TestReport
+	err := jenkins.get(ctx, path, nil, &report)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get test report for %s #%d: %w", job, buildNumber, err)
+	}
+
+	return &report, nil
+}
+
+// reportTests fetches and logs the test report of a finished build.
+// The counts reach the CI outputs through the job summary.
+// It returns nil if the build has no test report or it could not be fetched.
+func (jenkins *Jenkins) reportTests(ctx context.Context, job string, buildNumber int) *TestReport {
+	report, err := jenkins.getTestReport(ctx, job, buildNumber)
+	if err != nil {
+		slog.Warn("failed to get test report", "job", job, "build_number", buildNumber, "error", err)
+		return nil
+	}
+	if report == nil {
+		slog.Info("job has no test report", "job", job, "build_number", buildNumber)
+		return nil
+	}
+
+	slog.Info(
+		"test results",
+		"job", job,
+		"build_number", buildNumber,
+		"passed", report.Passed(),
+		"failed", report.FailCount,
+		"skipped", report.SkipCount,
+		"total", report.Total(),
+	)
+
+	failed := report.FailedCases()
+	for i, c := range failed {
+		if i == maxReportedFailures {
+			slog.Info(
+				"more failing tests not shown",
+				"job", job,
+				"build_number", buildNumber,
+				"count", len(failed)-maxReportedFailures,
+			)
+			break
+		}
+		slog.Info("test failed", "job", job, "build_number", buildNumber, "test", formatTestCase(c))
+	}
+
+	return report
+}
+
+// formatTestCase formats a failing test case with the first line of its error message
+func formatTestCase(c TestCase) string {
+	name := c.Name
+	if c.ClassName != "" {
+		name = c.ClassName + "." + c.Name
+	}
+
+	details, _, _ := strings.Cut(strings.TrimSpace(c.ErrorDetails), "\n")
+	if details == "" {
+		return name
+	}
+
+	return name + ": " + details
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testReportPath = "/job/test-job/456/testReport/api/json"
+
+func TestGetTestReport(t *testing.T) {
+	t.Run("junit report", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, testReportPath, r.URL.Path)
+			_, _ = w.Write([]byte(`{"failCount":2,"passCount":5,"skipCount":1,"suites":[{"cases":[` +
+				`{"className":"com.example.ApiTest","name":"testLogin","status":"PASSED"},` +
+				`{"className":"com.example.ApiTest","name":"testLogout","status":"FAILED",` +
+				`"errorDetails":"expected 200 but was 500\nat line 12"},` +
+				`{"className":"com.example.DbTest","name":"testMigrate","status":"REGRESSION"},` +
+				`{"className":"com.example.DbTest","name":"testSeed","status":"SKIPPED"}]}]}`))
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		report, err := jenkins.getTestReport(context.Background(), testJobName, 456)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, report.Passed())
+		assert.Equal(t, 8, report.Total())
+
+		failed := report.FailedCases()
+		assert.Len(t, failed, 2)
+		assert.Equal(
+			t,
+			"com.example.ApiTest.testLogout: expected 200 but was 500",
+			formatTestCase(failed[0]),
+		)
+		assert.Equal(t, "com.example.DbTest.testMigrate", formatTestCase(failed[1]))
+	})
+
+	t.Run("aggregated report", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"failCount":1,"skipCount":0,"totalCount":10,"childReports":[` +
+				`{"result":{"suites":[{"cases":[{"name":"testChild","status":"FAILED"}]}]}}]}`))
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		report, err := jenkins.getTestReport(context.Background(), testJobName, 456)
+		assert.NoError(t, err)
+		assert.Equal(t, 9, report.Passed())
+		assert.Equal(t, 10, report.Total())
+		assert.Len(t, report.FailedCases(), 1)
+	})
+
+	t.Run("no test report", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		report, err := jenkins.getTestReport(context.Background(), testJobName, 456)
+		assert.NoError(t, err)
+		assert.Nil(t, report)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		report, err := jenkins.getTestReport(context.Background(), testJobName, 456)
+		assert.Error(t, err)
+		assert.Nil(t, report)
+	})
+}
+
+// TestReportTests tests the counts are returned for the summary, without setting
+// outputs each job would overwrite
+func TestReportTests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"failCount":1,"passCount":3,"skipCount":2,"suites":[]}`))
+	}))
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "github_output")
+	assert.NoError(t, os.WriteFile(output, nil, 0o600))
+	t.Setenv("GITHUB_OUTPUT", output)
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+
+	report := jenkins.reportTests(context.Background(), testJobName, 456)
+	assert.NotNil(t, report)
+	assert.Equal(t, 6, report.Total())
+
+	assert.Equal(t, 3, report.Passed())
+	assert.Equal(t, 1, report.FailCount)
+	assert.Equal(t, 2, report.SkipCount)
+
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Empty(t, data)
+}
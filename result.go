@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Jenkins build results
+const (
+	resultSuccess  = "SUCCESS"
+	resultUnstable = "UNSTABLE"
+	resultFailure  = "FAILURE"
+	resultNotBuilt = "NOT_BUILT"
+	resultAborted  = "ABORTED"
+)
+
+// Process exit codes, distinct per Jenkins result so later steps can branch on them
+const (
+	exitError    = 1 // Configuration, trigger, timeout or other non-result errors
+	exitFailure  = 2
+	exitUnstable = 3
+	exitAborted  = 4
+	exitNotBuilt = 5
+)
+
+// knownResults lists every build result Jenkins can report for a finished build
+var knownResults = []string{resultSuccess, resultUnstable, resultFailure, resultNotBuilt, resultAborted}
+
+// exitCodes maps a build result to its exit code
+var exitCodes = map[string]int{
+	resultFailure:  exitFailure,
+	resultUnstable: exitUnstable,
+	resultAborted:  exitAborted,
+	resultNotBuilt: exitNotBuilt,
+}
+
+// exitSeverity ranks exit codes so the most severe one wins when several jobs fail
+var exitSeverity = map[int]int{
+	exitUnstable: 1,
+	exitNotBuilt: 2,
+	exitAborted:  3,
+	exitFailure:  4,
+	exitError:    5,
+}
+
+// ResultError reports a finished build whose result is not accepted
+type ResultError struct {
+	Job    string
+	Number int
+	Result string
+}
+
+// Error implements the error interface
+func (e *ResultError) Error() string {
+	return fmt.Sprintf("job %q (build #%d) failed with status: %s", e.Job, e.Number, e.Result)
+}
+
+// parseResults normalizes a list of build results and validates each of them
+func parseResults(results []string) ([]string, error) {
+	normalized := make([]string, 0, len(results))
+	for _, result := range trimWhitespaceFromSlice(results) {
+		result = strings.ToUpper(result)
+		if !slices.Contains(knownResults, result) {
+			return nil, fmt.Errorf(
+				"unknown build result %q (expected one of %s)",
+				result,
+				strings.Join(knownResults, ", "),
+			)
+		}
+		normalized = append(normalized, result)
+	}
+
+	return normalized, nil
+}
+
+// resultPolicy decides which build results are accepted, globally or per job
+type resultPolicy struct {
+	accept []string
+	perJob map[string][]string
+}
+
+// newResultPolicy builds a policy from the global accepted results and
+// the multi-line job=RESULT1,RESULT2 overrides. SUCCESS is accepted by default.
+func newResultPolicy(accept []string, perJob string) (*resultPolicy, error) {
+	global, err := parseResults(accept)
+	if err != nil {
+		return nil, err
+	}
+	if len(global) == 0 {
+		global = []string{resultSuccess}
+	}
+
+	policy := &resultPolicy{accept: global, perJob: map[string][]string{}}
+	for job, results := range parseJobLists(perJob) {
+		if policy.perJob[job], err = parseResults(results); err != nil {
+			return nil, fmt.Errorf("job %q: %w", job, err)
+		}
+	}
+
+	return policy, nil
+}
+
+// accepts reports whether the result of the given job is acceptable
+func (policy *resultPolicy) accepts(job, result string) bool {
+	if results, ok := policy.perJob[job]; ok {
+		return slices.Contains(results, result)
+	}
+
+	return slices.Contains(policy.accept, result)
+}
+
+// exitCode returns the process exit code for an error returned by Plugin.Exec.
+// When several jobs failed, the code of the most severe failure is returned.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	// Walk joined and wrapped errors down to the individual job failures
+	var multi interface{ Unwrap() []error }
+	if errors.As(err, &multi) {
+		code := 0
+		for _, e := range multi.Unwrap() {
+			if c := exitCode(e); exitSeverity[c] > exitSeverity[code] {
+				code = c
+			}
+		}
+		if code != 0 {
+			return code
+		}
+	}
+
+	var resultErr *ResultError
+	if errors.As(err, &resultErr) {
+		if code, ok := exitCodes[resultErr.Result]; ok {
+			return code
+		}
+	}
+
+	return exitError
+}
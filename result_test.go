@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewResultPolicy(t *testing.T) {
+	t.Run("defaults to SUCCESS", func(t *testing.T) {
+		policy, err := newResultPolicy(nil, "")
+		assert.NoError(t, err)
+		assert.True(t, policy.accepts(testJobName, resultSuccess))
+		assert.False(t, policy.accepts(testJobName, resultUnstable))
+	})
+
+	t.Run("global results are normalized", func(t *testing.T) {
+		policy, err := newResultPolicy([]string{" success ", "unstable"}, "")
+		assert.NoError(t, err)
+		assert.True(t, policy.accepts(testJobName, resultSuccess))
+		assert.True(t, policy.accepts(testJobName, resultUnstable))
+		assert.False(t, policy.accepts(testJobName, resultFailure))
+	})
+
+	t.Run("per job results override global", func(t *testing.T) {
+		policy, err := newResultPolicy(
+			[]string{resultSuccess},
+			"flaky-tests=SUCCESS,UNSTABLE\ncleanup=NOT_BUILT",
+		)
+		assert.NoError(t, err)
+		assert.True(t, policy.accepts("flaky-tests", resultUnstable))
+		assert.False(t, policy.accepts("cleanup", resultSuccess))
+		assert.True(t, policy.accepts("cleanup", resultNotBuilt))
+		assert.False(t, policy.accepts(testJobName, resultUnstable))
+	})
+
+	t.Run("unknown global result", func(t *testing.T) {
+		_, err := newResultPolicy([]string{"PASSED"}, "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown build result "PASSED"`)
+	})
+
+	t.Run("unknown per job result", func(t *testing.T) {
+		_, err := newResultPolicy(nil, "deploy=SUCCESS,BROKEN")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), `job "deploy"`)
+	})
+}
+
+func TestExitCode(t *testing.T) {
+	resultErr := func(result string) error {
+		return fmt.Errorf("wrapped: %w", &ResultError{Job: testJobName, Number: 1, Result: result})
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "no error", err: nil, expected: 0},
+		{name: "generic error", err: errors.New("boom"), expected: exitError},
+		{name: "failure", err: resultErr(resultFailure), expected: exitFailure},
+		{name: "unstable", err: resultErr(resultUnstable), expected: exitUnstable},
+		{name: "aborted", err: resultErr(resultAborted), expected: exitAborted},
+		{name: "not built", err: resultErr(resultNotBuilt), expected: exitNotBuilt},
+		{
+			name:     "most severe result wins",
+			err:      errors.Join(resultErr(resultUnstable), resultErr(resultFailure)),
+			expected: exitFailure,
+		},
+		{
+			name: "generic error outranks results",
+			err: fmt.Errorf(
+				"2 of 2 jobs failed: %w",
+				errors.Join(resultErr(resultAborted), errors.New("trigger failed")),
+			),
+			expected: exitError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exitCode(tt.err))
+		})
+	}
+}
+
+// TestExecAcceptResults tests the result acceptance policy when waiting for jobs
+func TestExecAcceptResults(t *testing.T) {
+	tests := []struct {
+		name             string
+		result           string
+		acceptResults    []string
+		jobAcceptResults string
+		wantCode         int
+	}{
+		{
+			name:     "unstable rejected by default",
+			result:   resultUnstable,
+			wantCode: exitUnstable,
+		},
+		{
+			name:          "unstable accepted globally",
+			result:        resultUnstable,
+			acceptResults: []string{resultSuccess, resultUnstable},
+		},
+		{
+			name:             "unstable accepted for the job",
+			result:           resultUnstable,
+			jobAcceptResults: testJobName + "=SUCCESS,UNSTABLE",
+		},
+		{
+			name:             "per job policy replaces global",
+			result:           resultUnstable,
+			acceptResults:    []string{resultSuccess, resultUnstable},
+			jobAcceptResults: testJobName + "=SUCCESS",
+			wantCode:         exitUnstable,
+		},
+		{
+			name:     "aborted",
+			result:   resultAborted,
+			wantCode: exitAborted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tj := newTestJenkins(t, map[string]string{testJobName: tt.result}, 1)
+
+			plugin := Plugin{
+				BaseURL:          tj.URL,
+				Username:         testUserFoo,
+				Token:            testUserBar,
+				Job:              []string{testJobName},
+				Wait:             true,
+				PollInterval:     10 * time.Millisecond,
+				AcceptResults:    tt.acceptResults,
+				JobAcceptResults: tt.jobAcceptResults,
+			}
+
+			err := plugin.Exec(context.Background())
+
+			if tt.wantCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "failed with status: "+tt.result)
+			assert.Equal(t, tt.wantCode, exitCode(err))
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Default retry policy for transient Jenkins failures
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = time.Second
+	defaultRetryMaxBackoff = 30 * time.Second
+)
+
+// defaultRetryStatusCodes are the response codes treated as transient by default
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy controls how requests failing with transient errors are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total number of attempts, 1 disables retries
+	Backoff     time.Duration // Delay before the first retry, doubled for every further retry
+	MaxBackoff  time.Duration // Upper bound of the delay between attempts, including Retry-After
+	StatusCodes []int         // Response codes that are retried
+}
+
+// defaultRetryPolicy returns the retry policy used by new Jenkins clients
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultRetryAttempts,
+		Backoff:     defaultRetryBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+		StatusCodes: slices.Clone(defaultRetryStatusCodes),
+	}
+}
+
+// delay returns the wait before the given retry (starting at 1),
+// using exponential backoff with equal jitter
+func (p RetryPolicy) delay(retry int) time.Duration {
+	backoff := p.Backoff
+	for i := 1; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	half := backoff / 2
+	return half + rand.N(backoff-half+1) // #nosec G404 -- jitter does not need a secure source
+}
+
+// retryAfter parses the Retry-After header given in seconds or as an HTTP date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// isTransientError reports whether a request error is a network failure worth retrying
+func isTransientError(err error) bool {
+	// url.Error implements net.Error itself, so look at the underlying cause
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isNotSent reports whether a request error happened before the request reached Jenkins
+func isNotSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryable reports whether a failed attempt may be repeated.
+// A POST (e.g. to /build) is only repeated when Jenkins cannot have acted on it:
+// the connection was never established, or Jenkins rejected the request
+// with 429 or 503 before handling it. Other errors may have enqueued a build.
+func (p RetryPolicy) retryable(method string, resp *http.Response, err error) bool {
+	if method != http.MethodPost {
+		if err != nil {
+			return isTransientError(err)
+		}
+		return slices.Contains(p.StatusCodes, resp.StatusCode)
+	}
+
+	if err != nil {
+		return isNotSent(err)
+	}
+
+	return (resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusServiceUnavailable) &&
+		slices.Contains(p.StatusCodes, resp.StatusCode)
+}
+
+// doWithRetry sends the request returned by newRequest, retrying transient failures
+// according to the retry policy. newRequest is called for every attempt so that
+// the request body can be recreated. A request whose OAuth2 access token is rejected
+// is sent once more with a new token. The returned response may have any status code.
+func (jenkins *Jenkins) doWithRetry(
+	ctx context.Context,
+	newRequest func() (*http.Request, error),
+	crumb *CrumbResponse,
+) (*http.Response, error) {
+	policy := jenkins.Retry
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := jenkins.sendRequest(req, crumb)
+		if jenkins.renewToken(req, resp, err) {
+			if req, err = newRequest(); err != nil {
+				return nil, err
+			}
+			resp, err = jenkins.sendRequest(req, crumb)
+		}
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil ||
+			!policy.retryable(req.Method, resp, err) {
+			return resp, err
+		}
+
+		wait := policy.delay(attempt)
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			if after, ok := retryAfter(resp.Header); ok {
+				// Honor the server's delay, but no longer than the policy allows
+				wait = after
+				if policy.MaxBackoff > 0 {
+					wait = min(after, policy.MaxBackoff)
+				}
+			}
+			reason = resp.Status
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+		}
+
+		slog.Warn(
+			"request failed, retrying",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"reason", reason,
+			"wait", wait.Round(time.Millisecond),
+			"attempt", attempt+1,
+			"max_attempts", policy.MaxAttempts,
+		)
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRetryJenkins creates a Jenkins client with a fast retry policy
+func newTestRetryJenkins(t *testing.T, baseURL string) *Jenkins {
+	t.Helper()
+
+	jenkins, err := NewJenkins(context.Background(), nil, baseURL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+	jenkins.Retry.Backoff = time.Millisecond
+	jenkins.Retry.MaxBackoff = 5 * time.Millisecond
+
+	return jenkins
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		retry   int
+		backoff time.Duration
+	}{
+		{retry: 1, backoff: 100 * time.Millisecond},
+		{retry: 2, backoff: 200 * time.Millisecond},
+		{retry: 3, backoff: 400 * time.Millisecond},
+		{retry: 5, backoff: time.Second},
+		{retry: 50, backoff: time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			delay := policy.delay(tt.retry)
+			assert.GreaterOrEqual(t, delay, tt.backoff/2)
+			assert.LessOrEqual(t, delay, tt.backoff)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	_, ok := retryAfter(header)
+	assert.False(t, ok)
+
+	header.Set("Retry-After", "3")
+	wait, ok := retryAfter(header)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, wait)
+
+	header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	wait, ok = retryAfter(header)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), wait)
+
+	header.Set("Retry-After", "soon")
+	_, ok = retryAfter(header)
+	assert.False(t, ok)
+}
+
+func TestRetryable(t *testing.T) {
+	policy := defaultRetryPolicy()
+	status := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+	dialErr := &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: errors.New("refused")}}
+	resetErr := &url.Error{Op: "Post", Err: &net.OpError{Op: "read", Err: errors.New("reset")}}
+
+	tests := []struct {
+		name     string
+		method   string
+		resp     *http.Response
+		err      error
+		expected bool
+	}{
+		{name: "GET 503", method: http.MethodGet, resp: status(503), expected: true},
+		{name: "GET 504", method: http.MethodGet, resp: status(504), expected: true},
+		{name: "GET 500", method: http.MethodGet, resp: status(500), expected: false},
+		{name: "GET 404", method: http.MethodGet, resp: status(404), expected: false},
+		{name: "GET connection reset", method: http.MethodGet, err: resetErr, expected: true},
+		{name: "GET unexpected EOF", method: http.MethodGet, err: io.ErrUnexpectedEOF, expected: true},
+		{
+			name:     "GET invalid URL",
+			method:   http.MethodGet,
+			err:      &url.Error{Op: "Get", Err: errors.New("unsupported protocol scheme")},
+			expected: false,
+		},
+		{name: "POST 503", method: http.MethodPost, resp: status(503), expected: true},
+		{name: "POST 429", method: http.MethodPost, resp: status(429), expected: true},
+		{name: "POST 502", method: http.MethodPost, resp: status(502), expected: false},
+		{name: "POST 504", method: http.MethodPost, resp: status(504), expected: false},
+		{name: "POST dial error", method: http.MethodPost, err: dialErr, expected: true},
+		{name: "POST connection reset", method: http.MethodPost, err: resetErr, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.retryable(tt.method, tt.resp, tt.err))
+		})
+	}
+}
+
+// TestRetryAfterLimited tests that a long Retry-After delay is limited to the maximum backoff
+func TestRetryAfterLimited(t *testing.T) {
+	for _, value := range []string{"86400", time.Now().Add(24 * time.Hour).UTC().Format(http.TimeFormat)} {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", value)
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"SUCCESS"}`))
+		}))
+
+		jenkins := newTestRetryJenkins(t, server.URL)
+
+		start := time.Now()
+		buildInfo, err := jenkins.getBuildInfo(context.Background(), testJobName, 456)
+		assert.NoError(t, err)
+		assert.Equal(t, "SUCCESS", buildInfo.Result)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		assert.Less(t, time.Since(start), time.Second)
+
+		server.Close()
+	}
+}
+
+func TestGetRetriesTransientStatus(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"SUCCESS"}`))
+	}))
+	defer server.Close()
+
+	jenkins := newTestRetryJenkins(t, server.URL)
+
+	buildInfo, err := jenkins.getBuildInfo(context.Background(), testJobName, 456)
+	assert.NoError(t, err)
+	assert.Equal(t, "SUCCESS", buildInfo.Result)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestGetRetriesExhausted(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	jenkins := newTestRetryJenkins(t, server.URL)
+	jenkins.Retry.MaxAttempts = 2
+
+	_, err := jenkins.getBuildInfo(context.Background(), testJobName, 456)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected response code: 502")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestTriggerRetries(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		expectedCalls int32
+		wantErr       bool
+	}{
+		{name: "service unavailable is retried", status: http.StatusServiceUnavailable, expectedCalls: 2},
+		{
+			name:          "gateway timeout is not retried",
+			status:        http.StatusGatewayTimeout,
+			expectedCalls: 1,
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Header().Set("Location", "http://jenkins.example.com/queue/item/123/")
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			jenkins := newTestRetryJenkins(t, server.URL)
+
+			queueID, err := jenkins.trigger(context.Background(), testJobName, nil, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 123, queueID)
+			}
+			assert.Equal(t, tt.expectedCalls, atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	jenkins := newTestRetryJenkins(t, server.URL)
+	jenkins.Retry.Backoff = time.Minute
+	jenkins.Retry.MaxBackoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := jenkins.getBuildInfo(ctx, testJobName, 456)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
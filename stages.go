@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type (
+	// Stage represents a Pipeline stage reported by the Workflow API
+	Stage struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		// NOT_EXECUTED, IN_PROGRESS, SUCCESS, FAILED, ABORTED, UNSTABLE or PAUSED_PENDING_INPUT
+		Status          string `json:"status"`
+		StartTimeMillis int64  `json:"startTimeMillis"`
+		DurationMillis  int64  `json:"durationMillis"`
+	}
+
+	// RunDescription represents the Workflow API description of a Pipeline run
+	RunDescription struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Status string  `json:"status"`
+		Stages []Stage `json:"stages"`
+	}
+)
+
+// Duration returns the stage duration
+func (s *Stage) Duration() time.Duration {
+	return time.Duration(s.DurationMillis) * time.Millisecond
+}
+
+// stageTracker logs Pipeline stage transitions of a single build
+type stageTracker struct {
+	jenkins     *Jenkins
+	job         string
+	buildNumber int
+	status      map[string]string // Last logged status by stage ID
+	stages      []Stage           // Stages of the latest description
+	disabled    bool              // Set when the build does not expose the Workflow API
+}
+
+// newStageTracker creates a stage tracker for the given build
+func (jenkins *Jenkins) newStageTracker(job string, buildNumber int) *stageTracker {
+	return &stageTracker{
+		jenkins:     jenkins,
+		job:         job,
+		buildNumber: buildNumber,
+		status:      map[string]string{},
+	}
+}
+
+// describeRun fetches the Workflow API description of a Pipeline build.
+// It returns nil without error if the build is not a Pipeline run.
+func (jenkins *Jenkins) describeRun(
+	ctx context.Context,
+	job string,
+	buildNumber int,
+) (*RunDescription, error) {
+	path := fmt.Sprintf("%s/%d/wfapi/describe", jenkins.parseJobPath(job), buildNumber)
+
+	var run RunDescription
+	if err := jenkins.get(ctx, path, nil, &run); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe %s #%d: %w", job, buildNumber, err)
+	}
+
+	return &run, nil
+}
+
+// poll fetches the stages of the build and logs every stage whose status changed
+func (t *stageTracker) poll(ctx context.Context) error {
+	if t.disabled {
+		return nil
+	}
+
+	run, err := t.jenkins.describeRun(ctx, t.job, t.buildNumber)
+	if err != nil {
+		return err
+	}
+	if run == nil {
+		// Not a Pipeline job, stop asking
+		t.disabled = true
+		return nil
+	}
+
+	t.stages = run.Stages
+	for _, stage := range run.Stages {
+		if t.status[stage.ID] == stage.Status {
+			continue
+		}
+		t.status[stage.ID] = stage.Status
+
+		switch stage.Status {
+		case "NOT_EXECUTED":
+			// Skipped or not yet reached stages are only shown in the final table
+		case "IN_PROGRESS":
+			slog.Info("stage started", "job", t.job, "build_number", t.buildNumber, "stage", stage.Name)
+		case "PAUSED_PENDING_INPUT":
+			slog.Info(
+				"stage is waiting for input",
+				"job", t.job,
+				"build_number", t.buildNumber,
+				"stage", stage.Name,
+			)
+		default:
+			slog.Info(
+				"stage finished",
+				"job", t.job,
+				"build_number", t.buildNumber,
+				"stage", stage.Name,
+				"result", stage.Status,
+				"duration", stage.Duration(),
+			)
+		}
+	}
+
+	return nil
+}
+
+// logSummary logs a table with the final result of every stage of the build
+func (t *stageTracker) logSummary() {
+	if len(t.stages) == 0 {
+		return
+	}
+
+	table := logTable{columns: []string{"stage", "result", "duration"}}
+	for _, stage := range t.stages {
+		table.rows = append(table.rows, []any{stage.Name, stage.Status, stage.Duration()})
+	}
+	slog.Info("stage results", "job", t.job, "build_number", t.buildNumber, "stages", table)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testStagesPath = "/job/test-job/456/wfapi/describe"
+
+// captureLog redirects the default logger to a buffer for the duration of the test,
+// writing text records without timestamps
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := slog.Default()
+	writer, flags := log.Writer(), log.Flags()
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return replaceAttr(groups, attr)
+		},
+	})
+	slog.SetDefault(slog.New(newTableHandler(handler, &buf, true)))
+	t.Cleanup(func() {
+		// Restoring the default logger also redirects the log package, so reset it last
+		slog.SetDefault(logger)
+		log.SetOutput(writer)
+		log.SetFlags(flags)
+	})
+
+	return &buf
+}
+
+func TestDescribeRun(t *testing.T) {
+	t.Run("pipeline run", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, testStagesPath, r.URL.Path)
+			_, _ = w.Write([]byte(`{"id":"456","status":"IN_PROGRESS","stages":[` +
+				`{"id":"6","name":"Build","status":"SUCCESS","durationMillis":1500},` +
+				`{"id":"12","name":"Test","status":"IN_PROGRESS","durationMillis":200}]}`))
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		run, err := jenkins.describeRun(context.Background(), testJobName, 456)
+		assert.NoError(t, err)
+		assert.Len(t, run.Stages, 2)
+		assert.Equal(t, "Build", run.Stages[0].Name)
+		assert.Equal(t, 1500*time.Millisecond, run.Stages[0].Duration())
+	})
+
+	t.Run("not a pipeline", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+		assert.NoError(t, err)
+
+		run, err := jenkins.describeRun(context.Background(), testJobName, 456)
+		assert.NoError(t, err)
+		assert.Nil(t, run)
+	})
+}
+
+func TestStageTracker(t *testing.T) {
+	responses := []string{
+		`{"stages":[{"id":"6","name":"Build","status":"IN_PROGRESS"}]}`,
+		`{"stages":[{"id":"6","name":"Build","status":"IN_PROGRESS"}]}`,
+		`{"stages":[{"id":"6","name":"Build","status":"SUCCESS","durationMillis":2000},` +
+			`{"id":"12","name":"Deploy","status":"FAILED","durationMillis":500}]}`,
+	}
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(responses[atomic.AddInt32(&calls, 1)-1]))
+	}))
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+
+	out := captureLog(t)
+	tracker := jenkins.newStageTracker(testJobName, 456)
+	for range responses {
+		assert.NoError(t, tracker.poll(context.Background()))
+	}
+	tracker.logSummary()
+
+	assert.Equal(t,
+		"level=INFO msg=\"stage started\" job=test-job build_number=456 stage=Build\n"+
+			"level=INFO msg=\"stage finished\" job=test-job build_number=456 stage=Build result=SUCCESS duration=2s\n"+
+			"level=INFO msg=\"stage finished\" job=test-job build_number=456 stage=Deploy result=FAILED duration=500ms\n"+
+			"level=INFO msg=\"stage results\" job=test-job build_number=456\n"+
+			"STAGE   RESULT   DURATION\n"+
+			"Build   SUCCESS  2s\n"+
+			"Deploy  FAILED   500ms\n",
+		out.String(),
+	)
+}
+
+func TestStageTrackerNotPipeline(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+
+	tracker := jenkins.newStageTracker(testJobName, 456)
+	assert.NoError(t, tracker.poll(context.Background()))
+	assert.NoError(t, tracker.poll(context.Background()))
+
+	// The Workflow API is only queried once for builds that are not Pipeline runs
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestWaitForCompletionStageProgress(t *testing.T) {
+	var buildCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case testQueueItemPath:
+			_, _ = w.Write([]byte(`{"id":123,"executable":{"number":456}}`))
+		case testBuildStatusPath:
+			if atomic.AddInt32(&buildCalls, 1) == 1 {
+				_, _ = w.Write([]byte(`{"number":456,"building":true,"result":null}`))
+			} else {
+				_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"FAILURE"}`))
+			}
+		case testStagesPath:
+			if atomic.LoadInt32(&buildCalls) < 1 {
+				_, _ = w.Write([]byte(`{"stages":[{"id":"6","name":"Deploy","status":"IN_PROGRESS"}]}`))
+			} else {
+				_, _ = w.Write([]byte(
+					`{"stages":[{"id":"6","name":"Deploy","status":"FAILED","durationMillis":1000}]}`,
+				))
+			}
+		}
+	}))
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+	jenkins.StageProgress = true
+
+	out := captureLog(t)
+	buildInfo, err := jenkins.waitForCompletion(
+		context.Background(),
+		testJobName,
+		123,
+		50*time.Millisecond,
+		5*time.Second,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "FAILURE", buildInfo.Result)
+	assert.Contains(t, out.String(), `msg="stage started" job=test-job build_number=456 stage=Deploy`)
+	assert.Contains(t, out.String(), `msg="stage finished" job=test-job build_number=456 stage=Deploy result=FAILED`)
+	assert.Contains(t, out.String(), `msg="stage results" job=test-job build_number=456`)
+	assert.Contains(t, out.String(), "Deploy  FAILED")
+}
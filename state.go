@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type (
+	// State is the content of the state file written when triggering jobs,
+	// used to resume waiting for the builds in a later step
+	State struct {
+		Jobs []JobState `json:"jobs"`
+	}
+
+	// JobState records a triggered job
+	JobState struct {
+		Job     string `json:"job"`
+		URL     string `json:"url"` // Jenkins server base URL
+		QueueID int    `json:"queue_id"`
+	}
+
+	// stateRecorder writes triggered jobs to the state file, safe for concurrent jobs
+	stateRecorder struct {
+		path  string
+		url   string
+		mu    sync.Mutex
+		state State
+	}
+)
+
+// newStateRecorder creates a recorder for the given state file, or nil if path is empty
+func newStateRecorder(path, baseURL string) *stateRecorder {
+	if path == "" {
+		return nil
+	}
+
+	return &stateRecorder{
+		path:  path,
+		url:   baseURL,
+		state: State{Jobs: []JobState{}},
+	}
+}
+
+// record adds a triggered job and rewrites the state file
+func (s *stateRecorder) record(job string, queueID int) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.Jobs = append(s.state.Jobs, JobState{Job: job, URL: s.url, QueueID: queueID})
+
+	return writeState(s.path, &s.state)
+}
+
+// writeState atomically writes the state file
+func writeState(path string, state *State) error {
+	if err := writeJSONFile(path, state); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	return nil
+}
+
+// writeJSONFile atomically writes v as indented JSON, so readers never see a partial file
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".drone-jenkins-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// readState reads the state file written by a previous trigger
+func readState(path string) (*State, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if len(state.Jobs) == 0 {
+		return nil, fmt.Errorf("state file %s contains no jobs", path)
+	}
+
+	return &state, nil
+}
+
+// resumeJobs selects the jobs to resume from the state, limited to the given jobs if any.
+// It returns the job names in state order, the state entries by job name and the
+// Jenkins URL the jobs were triggered on.
+func (s *State) resumeJobs(only []string) ([]string, map[string]JobState, string, error) {
+	entries := make(map[string]JobState, len(s.Jobs))
+	var jobs []string
+	baseURL := ""
+	for _, entry := range s.Jobs {
+		if baseURL == "" {
+			baseURL = entry.URL
+		} else if entry.URL != baseURL {
+			return nil, nil, "", fmt.Errorf(
+				"state file contains jobs of several Jenkins servers: %s and %s",
+				baseURL,
+				entry.URL,
+			)
+		}
+		if _, ok := entries[entry.Job]; !ok {
+			jobs = append(jobs, entry.Job)
+		}
+		entries[entry.Job] = entry
+	}
+
+	if len(only) > 0 {
+		for _, job := range only {
+			if _, ok := entries[job]; !ok {
+				return nil, nil, "", fmt.Errorf("job %q was not found in the state file", job)
+			}
+		}
+		jobs = only
+	}
+
+	return jobs, entries, baseURL, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jenkins-state.json")
+
+	recorder := newStateRecorder(path, testExampleURL)
+	assert.NoError(t, recorder.record("build", 10))
+	assert.NoError(t, recorder.record("deploy", 11))
+
+	state, err := readState(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []JobState{
+		{Job: "build", URL: testExampleURL, QueueID: 10},
+		{Job: "deploy", URL: testExampleURL, QueueID: 11},
+	}, state.Jobs)
+
+	// Recording without a state file is a no-op
+	var none *stateRecorder
+	assert.NoError(t, none.record("build", 1))
+}
+
+func TestReadState(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := readState(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read state file")
+
+	empty := filepath.Join(dir, "empty.json")
+	assert.NoError(t, os.WriteFile(empty, []byte(`{"jobs":[]}`), 0o600))
+	_, err = readState(empty)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "contains no jobs")
+
+	invalid := filepath.Join(dir, "invalid.json")
+	assert.NoError(t, os.WriteFile(invalid, []byte(`jobs`), 0o600))
+	_, err = readState(invalid)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse state file")
+}
+
+func TestResumeJobs(t *testing.T) {
+	state := &State{Jobs: []JobState{
+		{Job: "build", URL: testExampleURL, QueueID: 10},
+		{Job: "deploy", URL: testExampleURL, QueueID: 11},
+	}}
+
+	jobs, entries, baseURL, err := state.resumeJobs(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"build", "deploy"}, jobs)
+	assert.Equal(t, 11, entries["deploy"].QueueID)
+	assert.Equal(t, testExampleURL, baseURL)
+
+	jobs, _, _, err = state.resumeJobs([]string{"deploy"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deploy"}, jobs)
+
+	_, _, _, err = state.resumeJobs([]string{"test"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `job "test" was not found in the state file`)
+
+	state.Jobs = append(state.Jobs, JobState{Job: "other", URL: "http://other.example.com"})
+	_, _, _, err = state.resumeJobs(nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "several Jenkins servers")
+}
+
+// TestExecResume tests triggering jobs into a state file and waiting for them in a later run
+func TestExecResume(t *testing.T) {
+	tj := newTestJenkins(t, map[string]string{"job1": "SUCCESS", "job2": "SUCCESS"}, 2)
+	path := filepath.Join(t.TempDir(), "jenkins-state.json")
+
+	trigger := Plugin{
+		BaseURL:   tj.URL,
+		Username:  testUserFoo,
+		Token:     testUserBar,
+		Job:       []string{"job1", "job2"},
+		StateFile: path,
+	}
+	assert.NoError(t, trigger.Exec(context.Background()))
+
+	state, err := readState(path)
+	assert.NoError(t, err)
+	assert.Len(t, state.Jobs, 2)
+	assert.Equal(t, tj.URL, state.Jobs[0].URL)
+
+	// The host and jobs are taken from the state file
+	resume := Plugin{
+		Username:     testUserFoo,
+		Token:        testUserBar,
+		StateFile:    path,
+		Resume:       true,
+		PollInterval: 10 * time.Millisecond,
+	}
+	assert.NoError(t, resume.Exec(context.Background()))
+
+	// Resuming does not trigger the jobs again
+	assert.Equal(t, []string{"job1", "job2"}, tj.triggeredJobs())
+
+	t.Run("server mismatch", func(t *testing.T) {
+		resume.BaseURL = testExampleURL
+		err := resume.Exec(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "state file was written for")
+	})
+
+	t.Run("missing state file", func(t *testing.T) {
+		resume.StateFile = ""
+		err := resume.Exec(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "state file is required to resume")
+	})
+}
+
+// TestWaitForCompletionExpiredQueueItem tests finding the build of a queue item Jenkins forgot
+func TestWaitForCompletionExpiredQueueItem(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/test-job/api/json":
+			assert.Equal(t, "builds[number,queueId]{0,100}", r.URL.Query().Get("tree"))
+			_, _ = w.Write([]byte(`{"builds":[{"number":457,"queueId":124},{"number":456,"queueId":123}]}`))
+		case testBuildStatusPath:
+			_, _ = w.Write([]byte(`{"number":456,"building":false,"result":"SUCCESS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+
+	buildInfo, err := jenkins.waitForCompletion(
+		context.Background(),
+		testJobName,
+		123,
+		10*time.Millisecond,
+		5*time.Second,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 456, buildInfo.Number)
+}
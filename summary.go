@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Status of a job in the summary file, besides the job graph statuses
+const (
+	jobTriggered = "triggered" // Triggered without waiting for the build
+	jobTimedOut  = "timed_out"
+	jobCancelled = "cancelled"
+	jobNotRun    = "not_run" // Never triggered as an earlier job failed
+)
+
+type (
+	// Summary is the content of the summary file written after every run
+	Summary struct {
+		Jobs []JobSummary `json:"jobs"`
+	}
+
+	// JobSummary records the outcome of a single job
+	JobSummary struct {
+		Job         string      `json:"job"`
+		Status      string      `json:"status"`
+		QueueID     int         `json:"queue_id,omitempty"`
+		BuildNumber int         `json:"build_number,omitempty"`
+		URL         string      `json:"url,omitempty"`
+		Result      string      `json:"result,omitempty"`
+		DurationMs  int64       `json:"duration_ms,omitempty"`
+		Tests       *TestCounts `json:"tests,omitempty"`
+		Error       string      `json:"error,omitempty"`
+	}
+
+	// TestCounts holds the test counts of a build's test report
+	TestCounts struct {
+		Passed  int `json:"passed"`
+		Failed  int `json:"failed"`
+		Skipped int `json:"skipped"`
+		Total   int `json:"total"`
+	}
+
+	// summaryRecorder collects the outcome of every job, safe for concurrent jobs
+	summaryRecorder struct {
+		jobs []string
+		mu   sync.Mutex
+		done map[string]JobSummary
+	}
+)
+
+// newSummaryRecorder creates a recorder for the given jobs
+func newSummaryRecorder(jobs []string) *summaryRecorder {
+	return &summaryRecorder{
+		jobs: jobs,
+		done: map[string]JobSummary{},
+	}
+}
+
+// record stores the outcome of a job run. build is nil when the job was not waited for
+// or waiting failed, queueID is zero when the job could not be triggered and
+// report is nil when no test report was fetched.
+func (s *summaryRecorder) record(
+	job string,
+	queueID int,
+	build *BuildInfo,
+	report *TestReport,
+	err error,
+) {
+	if s == nil {
+		return
+	}
+
+	entry := JobSummary{Job: job, Status: summaryStatus(build, err), QueueID: queueID}
+	if build != nil {
+		entry.BuildNumber = build.Number
+		entry.URL = build.URL
+		entry.Result = build.Result
+		entry.DurationMs = build.Duration
+	}
+	if report != nil {
+		entry.Tests = &TestCounts{
+			Passed:  report.Passed(),
+			Failed:  report.FailCount,
+			Skipped: report.SkipCount,
+			Total:   report.Total(),
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done[job] = entry
+}
+
+// skip records a job that was not run, with the given status
+func (s *summaryRecorder) skip(job, status string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done[job] = JobSummary{Job: job, Status: status}
+}
+
+// summary returns one record per job in the configured order.
+// Jobs without a recorded outcome are reported as not run.
+func (s *summaryRecorder) summary() *Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := &Summary{Jobs: make([]JobSummary, 0, len(s.jobs))}
+	for _, job := range s.jobs {
+		entry, ok := s.done[job]
+		if !ok {
+			entry = JobSummary{Job: job, Status: jobNotRun}
+		}
+		summary.Jobs = append(summary.Jobs, entry)
+	}
+
+	return summary
+}
+
+// writeSummaryFile atomically writes the summary file
+func writeSummaryFile(path string, summary *Summary) error {
+	if err := writeJSONFile(path, summary); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+
+	return nil
+}
+
+// displayResult returns the build result, or the job status without a result
+func (j JobSummary) displayResult() string {
+	if j.Result != "" {
+		return j.Result
+	}
+
+	return j.Status
+}
+
+// buildLink returns a Markdown link to the build, or "-" without a build
+func (j JobSummary) buildLink() string {
+	if j.BuildNumber == 0 {
+		return "-"
+	}
+
+	build := "#" + strconv.Itoa(j.BuildNumber)
+	if j.URL == "" {
+		return build
+	}
+
+	return "[" + build + "](" + j.URL + ")"
+}
+
+// duration returns the build duration rounded to the second, or "-" without a build
+func (j JobSummary) duration() string {
+	if j.DurationMs <= 0 {
+		return "-"
+	}
+
+	return (time.Duration(j.DurationMs) * time.Millisecond).Round(time.Second).String()
+}
+
+// summaryStatus returns the summary status of a job run
+func summaryStatus(build *BuildInfo, err error) string {
+	switch {
+	case err == nil && build == nil:
+		return jobTriggered
+	case err == nil:
+		return nodeSucceeded
+	case errors.Is(err, errTimeout):
+		return jobTimedOut
+	case errors.Is(err, context.Canceled):
+		return jobCancelled
+	default:
+		return nodeFailed
+	}
+}
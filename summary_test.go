@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readSummary reads the summary file written by a run
+func readSummary(t *testing.T, path string) Summary {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var summary Summary
+	assert.NoError(t, json.Unmarshal(data, &summary))
+	return summary
+}
+
+func TestSummaryStatus(t *testing.T) {
+	build := &BuildInfo{Number: 1, Result: resultSuccess}
+
+	assert.Equal(t, jobTriggered, summaryStatus(nil, nil))
+	assert.Equal(t, nodeSucceeded, summaryStatus(build, nil))
+	assert.Equal(t, nodeFailed, summaryStatus(build, &ResultError{Job: testJobName, Result: resultFailure}))
+	assert.Equal(t, jobTimedOut, summaryStatus(nil, fmt.Errorf("%w waiting for job", errTimeout)))
+	assert.Equal(t, jobCancelled, summaryStatus(nil, fmt.Errorf("waiting: %w", context.Canceled)))
+	assert.Equal(t, nodeFailed, summaryStatus(nil, errors.New("failed to trigger job")))
+}
+
+func TestSummaryRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+
+	recorder := newSummaryRecorder([]string{"build", "test", "deploy"})
+	recorder.record("build", 3, &BuildInfo{
+		Number:   7,
+		Result:   resultSuccess,
+		URL:      testExampleURL + "/job/build/7/",
+		Duration: 1500,
+	}, &TestReport{PassCount: 4, FailCount: 1}, nil)
+	recorder.skip("deploy", nodeSkipped)
+	assert.NoError(t, writeSummaryFile(path, recorder.summary()))
+
+	assert.Equal(t, []JobSummary{
+		{
+			Job:         "build",
+			Status:      nodeSucceeded,
+			QueueID:     3,
+			BuildNumber: 7,
+			URL:         testExampleURL + "/job/build/7/",
+			Result:      resultSuccess,
+			DurationMs:  1500,
+			Tests:       &TestCounts{Passed: 4, Failed: 1, Total: 5},
+		},
+		{Job: "test", Status: jobNotRun},
+		{Job: "deploy", Status: nodeSkipped},
+	}, readSummary(t, path).Jobs)
+
+	// Recording without a recorder is a no-op
+	var none *summaryRecorder
+	none.record("build", 1, nil, nil, nil)
+	none.skip("build", nodeSkipped)
+}
+
+// TestExecSummaryFile tests writing the summary file after a run with failed and skipped jobs
+func TestExecSummaryFile(t *testing.T) {
+	t.Run("job graph", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{
+			"build":  "SUCCESS",
+			"test":   "FAILURE",
+			"deploy": "SUCCESS",
+		}, 1)
+		path := filepath.Join(t.TempDir(), "summary.json")
+
+		plugin := Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"build", "test", "deploy"},
+			DependsOn:    "test=build\ndeploy=test",
+			PollInterval: 10 * time.Millisecond,
+			SummaryFile:  path,
+		}
+
+		err := plugin.Exec(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, exitFailure, exitCode(err))
+
+		jobs := readSummary(t, path).Jobs
+		assert.Len(t, jobs, 3)
+		assert.Equal(t, JobSummary{
+			Job:         "build",
+			Status:      nodeSucceeded,
+			QueueID:     1,
+			BuildNumber: 1,
+			URL:         tj.URL + "/job/build/1/",
+			Result:      resultSuccess,
+		}, jobs[0])
+		assert.Equal(t, nodeFailed, jobs[1].Status)
+		assert.Equal(t, resultFailure, jobs[1].Result)
+		assert.Contains(t, jobs[1].Error, "failed with status: FAILURE")
+		assert.Equal(t, JobSummary{Job: "deploy", Status: nodeSkipped}, jobs[2])
+	})
+
+	t.Run("timeout after build started", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{"build": "SUCCESS"}, 1000)
+		path := filepath.Join(t.TempDir(), "summary.json")
+
+		plugin := Plugin{
+			BaseURL:      tj.URL,
+			Username:     testUserFoo,
+			Token:        testUserBar,
+			Job:          []string{"build"},
+			Wait:         true,
+			PollInterval: 10 * time.Millisecond,
+			Timeout:      100 * time.Millisecond,
+			SummaryFile:  path,
+		}
+
+		err := plugin.Exec(context.Background())
+		assert.ErrorIs(t, err, errTimeout)
+
+		jobs := readSummary(t, path).Jobs
+		assert.Len(t, jobs, 1)
+		assert.Equal(t, jobTimedOut, jobs[0].Status)
+		assert.Equal(t, 1, jobs[0].QueueID)
+		assert.Equal(t, 1, jobs[0].BuildNumber)
+		assert.Equal(t, tj.URL+"/job/build/1/", jobs[0].URL)
+		assert.Empty(t, jobs[0].Result)
+		assert.Contains(t, jobs[0].Error, "waiting for job build build #1 to complete")
+	})
+
+	t.Run("failed trigger", func(t *testing.T) {
+		tj := newTestJenkins(t, map[string]string{"build": "SUCCESS"}, 1)
+		path := filepath.Join(t.TempDir(), "summary.json")
+
+		plugin := Plugin{
+			BaseURL:     tj.URL,
+			Username:    testUserFoo,
+			Token:       testUserBar,
+			Job:         []string{"build", "missing/job", "deploy"},
+			SummaryFile: path,
+		}
+
+		err := plugin.Exec(context.Background())
+		assert.Error(t, err)
+
+		jobs := readSummary(t, path).Jobs
+		assert.Equal(t, JobSummary{Job: "build", Status: jobTriggered, QueueID: 1}, jobs[0])
+		assert.Equal(t, "missing/job", jobs[1].Job)
+		assert.Equal(t, nodeFailed, jobs[1].Status)
+		assert.Contains(t, jobs[1].Error, "failed to trigger job")
+		assert.Equal(t, JobSummary{Job: "deploy", Status: jobNotRun}, jobs[2])
+	})
+}
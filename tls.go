@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/youmark/pkcs8"
+)
+
+// loadClientCert loads a client certificate and its private key, decrypting the key
+// with the passphrase if needed. The certificate and key accept the same sources as
+// the CA certificate.
+func loadClientCert(
+	ctx context.Context,
+	client *http.Client,
+	cert, key, passphrase string,
+) (tls.Certificate, error) {
+	if cert == "" || key == "" {
+		return tls.Certificate{}, errors.New("client certificate and key must be set together")
+	}
+
+	certPEM, err := loadPEM(ctx, client, cert, "client certificate")
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyPEM, err := loadPEM(ctx, client, key, "client key")
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyPEM, err = decryptKeyPEM(keyPEM, passphrase)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// decryptKeyPEM returns the first private key of the PEM data, decrypting
+// an encrypted PKCS#8 key or a legacy OpenSSL encrypted key with the passphrase
+func decryptKeyPEM(data []byte, passphrase string) ([]byte, error) {
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			// Leave reporting the missing key to tls.X509KeyPair
+			return data, nil
+		}
+
+		//nolint:staticcheck // Legacy encrypted keys are still produced by openssl -traditional
+		legacy := x509.IsEncryptedPEMBlock(block)
+		if block.Type != "ENCRYPTED PRIVATE KEY" && !legacy {
+			continue
+		}
+
+		if passphrase == "" {
+			return nil, errors.New("client key is encrypted, a passphrase is required")
+		}
+
+		if legacy {
+			//nolint:staticcheck // See above
+			der, err := x509.DecryptPEMBlock(block, []byte(passphrase))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt client key: %w", err)
+			}
+			return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+		}
+
+		key, err := pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt client key: %w", err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode client key: %w", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+}
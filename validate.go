@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Modes of the parameter validation
+const (
+	validateOff  = "off"
+	validateWarn = "warn"
+	validateFail = "fail"
+)
+
+// defaultedParameterTypes are the built-in parameter types that always have a default value,
+// so a definition of these types without one is required. Other types, such as file, run
+// and credentials parameters, may be optional without a default.
+//
+// Jenkins fills in a default for these types even if none was configured (an empty string,
+// false or the first choice). The only exception is a choice parameter without choices,
+// which cannot be triggered with any value, so that is what the check catches in practice,
+// along with API responses that leave out the default.
+var defaultedParameterTypes = []string{
+	"StringParameterDefinition",
+	"TextParameterDefinition",
+	"BooleanParameterDefinition",
+	"ChoiceParameterDefinition",
+	"PasswordParameterDefinition",
+}
+
+type (
+	// ParameterDefinition represents a build parameter defined on a Jenkins job
+	ParameterDefinition struct {
+		Name                  string          `json:"name"`
+		Type                  string          `json:"type"` // e.g. ChoiceParameterDefinition
+		Choices               []string        `json:"choices"`
+		DefaultParameterValue *ParameterValue `json:"defaultParameterValue"` // nil if required or optional
+	}
+
+	// ParameterValue represents the value of a build parameter
+	ParameterValue struct {
+		Value interface{} `json:"value"`
+	}
+)
+
+// parseValidateMode normalizes the parameter validation mode, defaulting to off
+func parseValidateMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "", validateOff:
+		return validateOff, nil
+	case validateWarn, validateFail:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(
+			"unknown parameter validation mode %q (expected %s, %s or %s)",
+			mode,
+			validateOff,
+			validateWarn,
+			validateFail,
+		)
+	}
+}
+
+// getParameterDefinitions fetches the build parameters defined on a job
+func (jenkins *Jenkins) getParameterDefinitions(
+	ctx context.Context,
+	job string,
+) ([]ParameterDefinition, error) {
+	path := jenkins.parseJobPath(job) + "/api/json"
+	params := url.Values{"tree": []string{
+		"property[parameterDefinitions[name,type,choices,defaultParameterValue[value]]]",
+	}}
+
+	var jobInfo struct {
+		Property []struct {
+			ParameterDefinitions []ParameterDefinition `json:"parameterDefinitions"`
+		} `json:"property"`
+	}
+	if err := jenkins.get(ctx, path, params, &jobInfo); err != nil {
+		return nil, fmt.Errorf("failed to get parameter definitions of %s: %w", job, err)
+	}
+
+	var definitions []ParameterDefinition
+	for _, property := range jobInfo.Property {
+		definitions = append(definitions, property.ParameterDefinitions...)
+	}
+
+	return definitions, nil
+}
+
+// validateParameters checks the parameters against the job's definitions.
+// It returns the problems found, the defaults Jenkins applies to parameters that are not set
+// and the parameters that are not set and have no default, but whose type may be optional.
+func validateParameters(definitions []ParameterDefinition, params url.Values) ([]string, []string, []string) {
+	var problems, defaults, unset []string
+
+	byName := make(map[string]ParameterDefinition, len(definitions))
+	for _, def := range definitions {
+		byName[def.Name] = def
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		def, ok := byName[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown parameter %q", name))
+			continue
+		}
+
+		for _, value := range params[name] {
+			switch def.Type {
+			case "ChoiceParameterDefinition":
+				if !slices.Contains(def.Choices, value) {
+					problems = append(problems, fmt.Sprintf(
+						"invalid value %q for choice parameter %q (choices: %s)",
+						value,
+						name,
+						strings.Join(def.Choices, ", "),
+					))
+				}
+			case "BooleanParameterDefinition":
+				if !strings.EqualFold(value, "true") && !strings.EqualFold(value, "false") {
+					problems = append(problems, fmt.Sprintf(
+						"invalid value %q for boolean parameter %q (expected true or false)",
+						value,
+						name,
+					))
+				}
+			}
+		}
+	}
+
+	for _, def := range definitions {
+		if _, ok := params[def.Name]; ok {
+			continue
+		}
+
+		switch {
+		case def.DefaultParameterValue == nil && slices.Contains(defaultedParameterTypes, def.Type):
+			problems = append(problems, fmt.Sprintf("missing required parameter %q", def.Name))
+		case def.DefaultParameterValue == nil:
+			unset = append(unset, def.Name)
+		case def.Type == "PasswordParameterDefinition":
+			defaults = append(defaults, def.Name+"=***MASKED***")
+		default:
+			defaults = append(defaults, fmt.Sprintf("%s=%v", def.Name, def.DefaultParameterValue.Value))
+		}
+	}
+
+	return problems, defaults, unset
+}
+
+// checkParameters validates the parameters of a job before it is triggered,
+// failing or only warning about problems depending on the mode
+func (jenkins *Jenkins) checkParameters(
+	ctx context.Context,
+	job string,
+	params url.Values,
+	mode string,
+) error {
+	if mode == validateOff {
+		return nil
+	}
+
+	definitions, err := jenkins.getParameterDefinitions(ctx, job)
+	if err != nil {
+		if mode == validateFail {
+			return err
+		}
+		slog.Warn("skipping parameter validation", "job", job, "error", err)
+		return nil
+	}
+
+	problems, defaults, unset := validateParameters(definitions, params)
+	for _, value := range defaults {
+		slog.Info("parameter not set, using default", "job", job, "parameter", value)
+	}
+	for _, name := range unset {
+		slog.Warn("parameter not set and has no default", "job", job, "parameter", name)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	if mode == validateFail {
+		return fmt.Errorf("invalid parameters for job %q: %s", job, strings.Join(problems, "; "))
+	}
+	for _, problem := range problems {
+		slog.Warn("invalid parameter", "job", job, "problem", problem)
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testParameterDefinitions = `{"property":[{},{"parameterDefinitions":[
+	{"name":"VERSION","type":"StringParameterDefinition","defaultParameterValue":{"value":"latest"}},
+	{"name":"ENV","type":"ChoiceParameterDefinition","choices":["staging","production"],
+	 "defaultParameterValue":{"value":"staging"}},
+	{"name":"DRY_RUN","type":"BooleanParameterDefinition","defaultParameterValue":{"value":false}},
+	{"name":"API_KEY","type":"PasswordParameterDefinition","defaultParameterValue":{"value":"secret"}},
+	{"name":"TARGET","type":"RunParameterDefinition"}
+]}]}`
+
+func TestParseValidateMode(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":      validateOff,
+		"off":   validateOff,
+		"WARN":  validateWarn,
+		" fail": validateFail,
+	} {
+		mode, err := parseValidateMode(input)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, mode)
+	}
+
+	_, err := parseValidateMode("strict")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown parameter validation mode "strict"`)
+}
+
+func TestGetParameterDefinitions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/job/test-job/api/json", r.URL.Path)
+		assert.Contains(t, r.URL.Query().Get("tree"), "parameterDefinitions")
+		_, _ = w.Write([]byte(testParameterDefinitions))
+	}))
+	defer server.Close()
+
+	jenkins, err := NewJenkins(context.Background(), nil, server.URL, "", false, "", "", "", "", "", false)
+	assert.NoError(t, err)
+
+	definitions, err := jenkins.getParameterDefinitions(context.Background(), testJobName)
+	assert.NoError(t, err)
+	assert.Len(t, definitions, 5)
+	assert.Equal(t, []string{"staging", "production"}, definitions[1].Choices)
+	assert.Nil(t, definitions[4].DefaultParameterValue)
+}
+
+func TestValidateParameters(t *testing.T) {
+	definitions := []ParameterDefinition{
+		{
+			Name:                  "ENV",
+			Type:                  "ChoiceParameterDefinition",
+			Choices:               []string{"staging", "production"},
+			DefaultParameterValue: &ParameterValue{Value: "staging"},
+		},
+		{
+			Name:                  "DRY_RUN",
+			Type:                  "BooleanParameterDefinition",
+			DefaultParameterValue: &ParameterValue{Value: false},
+		},
+		{Name: "TARGET", Type: "RunParameterDefinition"},
+		{Name: "VERSION", Type: "StringParameterDefinition"},
+		{Name: "CONFIG", Type: "FileParameterDefinition"},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		problems, defaults, unset := validateParameters(definitions, url.Values{
+			"ENV":     {"production"},
+			"TARGET":  {"build#1"},
+			"VERSION": {"1.0"},
+		})
+		assert.Empty(t, problems)
+		assert.Equal(t, []string{"DRY_RUN=false"}, defaults)
+		assert.Equal(t, []string{"CONFIG"}, unset)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		problems, _, unset := validateParameters(definitions, url.Values{
+			"ENV":     {"prod"},
+			"DRY_RUN": {"yes"},
+			"VERSON":  {"1.0"},
+		})
+		assert.Equal(t, []string{
+			`invalid value "yes" for boolean parameter "DRY_RUN" (expected true or false)`,
+			`invalid value "prod" for choice parameter "ENV" (choices: staging, production)`,
+			`unknown parameter "VERSON"`,
+			`missing required parameter "VERSION"`,
+		}, problems)
+		assert.Equal(t, []string{"TARGET", "CONFIG"}, unset)
+	})
+}
+
+// TestExecValidateParameters tests failing or warning on invalid parameters before triggering
+func TestExecValidateParameters(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		wantErr   bool
+		triggered int32
+	}{
+		{name: "fail", mode: validateFail, wantErr: true, triggered: 0},
+		{name: "warn", mode: validateWarn, triggered: 1},
+		{name: "off", mode: "", triggered: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var triggered int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/job/test-job/api/json":
+					_, _ = w.Write([]byte(testParameterDefinitions))
+				case "/job/test-job/buildWithParameters":
+					atomic.AddInt32(&triggered, 1)
+					w.Header().Set("Location", "http://jenkins.example.com/queue/item/1/")
+					w.WriteHeader(http.StatusCreated)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			plugin := Plugin{
+				BaseURL:        server.URL,
+				Username:       testUserFoo,
+				Token:          testUserBar,
+				Job:            []string{testJobName},
+				Parameters:     "ENV=prod\nTARGET=build#1",
+				ValidateParams: tt.mode,
+			}
+
+			err := plugin.Exec(context.Background())
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), `invalid value "prod" for choice parameter "ENV"`)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.triggered, atomic.LoadInt32(&triggered))
+		})
+	}
+}